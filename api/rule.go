@@ -4,8 +4,11 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 
+	"github.com/ory/oathkeeper/pipeline/authn"
+	"github.com/ory/oathkeeper/pipeline/authz"
 	"github.com/ory/oathkeeper/rule"
 	"github.com/ory/oathkeeper/x"
 
@@ -27,6 +30,7 @@ type RuleHandler struct {
 type ruleHandlerRegistry interface {
 	x.RegistryWriter
 	rule.Registry
+	PipelineAuthorizer(id string) (authz.Authorizer, error)
 }
 
 func NewRuleHandler(r ruleHandlerRegistry) *RuleHandler {
@@ -38,6 +42,11 @@ func (h *RuleHandler) SetRoutes(r *x.RouterAPI) {
 	r.GET(RulesPath+"/:id", h.getRules)
 }
 
+// SetPreviewRoutes registers the rule preview endpoint. It is only meant to be served while authoring rules.
+func (h *RuleHandler) SetPreviewRoutes(r *x.RouterAPI) {
+	r.POST(RulesPath+"/:id/preview", h.previewRule)
+}
+
 // swagger:route GET /rules api listRules
 //
 // # List All Rules
@@ -101,3 +110,60 @@ func (h *RuleHandler) getRules(w http.ResponseWriter, r *http.Request, ps httpro
 
 	h.r.Writer().Write(w, r, rl)
 }
+
+// swagger:route POST /rules/{id}/preview api previewRule
+//
+// # Preview the Authorizer Request of a Rule
+//
+// Use this method to render the request the authorizer of a rule would send for the session in the request body,
+// without sending it. Values of rendered headers are masked. This endpoint is only served if
+// `serve.api.rule_preview.enabled` is set.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: authorizerPreview
+//	  400: genericError
+//	  404: genericError
+//	  500: genericError
+func (h *RuleHandler) previewRule(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	rl, err := h.r.RuleRepository().Get(r.Context(), ps.ByName("id"))
+	if errors.Cause(err) == helper.ErrResourceNotFound {
+		h.r.Writer().WriteErrorCode(w, r, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	var session authn.AuthenticationSession
+	if err := json.NewDecoder(r.Body).Decode(&session); err != nil {
+		h.r.Writer().WriteError(w, r, errors.WithStack(helper.ErrBadRequest.WithReasonf("The request body is not a valid authentication session: %s", err)))
+		return
+	}
+
+	a, err := h.r.PipelineAuthorizer(rl.Authorizer.Handler)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+	previewer, ok := a.(authz.Previewer)
+	if !ok {
+		h.r.Writer().WriteError(w, r, errors.WithStack(helper.ErrBadRequest.WithReasonf(`Authorizer "%s" does not support previews.`, a.GetID())))
+		return
+	}
+
+	preview, err := previewer.Preview(&session, rl.Authorizer.Config, rl)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, errors.WithStack(helper.ErrBadRequest.WithReasonf("Unable to render the authorizer request: %s", err)))
+		return
+	}
+
+	h.r.Writer().Write(w, r, preview)
+}
@@ -40,6 +40,37 @@ type swaggerGetRuleParameters struct {
 	ID string `json:"id"`
 }
 
+// swagger:parameters previewRule
+type swaggerPreviewRuleParameters struct {
+	// in: path
+	// required: true
+	ID string `json:"id"`
+
+	// The authentication session to render the authorizer request for.
+	// in: body
+	// required: true
+	Body map[string]interface{}
+}
+
+// The request an authorizer would send
+// swagger:response authorizerPreview
+type swaggerAuthorizerPreviewResponse struct {
+	// in: body
+	Body struct {
+		// The rendered remote URL.
+		Remote string `json:"remote,omitempty"`
+
+		// The rendered request body.
+		Body map[string]interface{} `json:"body"`
+
+		// The request headers. Values of rendered headers are masked.
+		Header map[string][]string `json:"header"`
+
+		// Set if the authorizer would allow the request without calling the remote.
+		Skipped bool `json:"skipped,omitempty"`
+	}
+}
+
 // swagger:model ruleMatch
 type swaggerRuleMatch struct {
 	// An array of HTTP methods (e.g. GET, POST, PUT, DELETE, ...). When ORY Oathkeeper searches for rules
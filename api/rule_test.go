@@ -7,13 +7,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
 
 	"github.com/ory/oathkeeper/driver/configuration"
+	"github.com/ory/oathkeeper/pipeline/authz"
 	"github.com/ory/oathkeeper/x"
 
+	"github.com/ory/x/configx"
 	"github.com/ory/x/pointerx"
 
 	"github.com/ory/oathkeeper/internal"
@@ -139,3 +143,96 @@ func TestHandler(t *testing.T) {
 
 	})
 }
+
+func TestPreviewHandler(t *testing.T) {
+	conf := internal.NewConfigurationWithDefaults(
+		configx.WithValue("authorizers.remote_json.enabled", true),
+		configx.WithValue("authorizers.remote_json.config.remote", "http://policy.invalid/authorize"),
+		configx.WithValue("authorizers.remote_json.config.payload", "{}"),
+	)
+	reg := internal.NewRegistry(conf)
+
+	router := x.NewAPIRouter()
+	reg.RuleHandler().SetRoutes(router)
+	reg.RuleHandler().SetPreviewRoutes(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	reg.RuleRepository().(*rule.RepositoryMemory).WithRules([]rule.Rule{
+		{
+			ID:    "remote",
+			Match: &rule.Match{URL: "https://localhost/<.*>", Methods: []string{"GET"}},
+			Authorizer: rule.Handler{Handler: "remote_json", Config: json.RawMessage(`{
+				"remote": "http://policy.invalid/tenants/{{ .Extra.tenant }}",
+				"payload": "{\"subject\":\"{{ .Subject }}\"}",
+				"headers": {"Authorization": "Bearer {{ .Extra.token }}", "X-Subject": "{{ .Subject }}"}
+			}`)},
+		},
+		{
+			ID:    "empty",
+			Match: &rule.Match{URL: "https://localhost/<.*>", Methods: []string{"PUT"}},
+			Authorizer: rule.Handler{Handler: "remote_json", Config: json.RawMessage(`{
+				"payload": "{{ if .Extra.admin }}{}{{ end }}",
+				"empty_payload": "allow"
+			}`)},
+		},
+		{
+			ID:         "invalid",
+			Match:      &rule.Match{URL: "https://localhost/<.*>", Methods: []string{"PATCH"}},
+			Authorizer: rule.Handler{Handler: "remote_json", Config: json.RawMessage(`{"payload": "subject {{ .Extra.token }}"}`)},
+		},
+		{
+			ID:         "allow",
+			Match:      &rule.Match{URL: "https://localhost/<.*>", Methods: []string{"POST"}},
+			Authorizer: rule.Handler{Handler: "allow"},
+		},
+	})
+
+	preview := func(t *testing.T, id string) *http.Response {
+		res, err := server.Client().Post(server.URL+"/rules/"+id+"/preview", "application/json", bytes.NewBufferString(`{"subject":"alice","extra":{"token":"secret","tenant":"acme"}}`))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res
+	}
+
+	t.Run("case=renders the request", func(t *testing.T) {
+		res := preview(t, "remote")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var body authz.Preview
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+		assert.Equal(t, "http://policy.invalid/tenants/acme", body.Remote)
+		assert.JSONEq(t, `{"subject":"alice"}`, string(body.Body))
+		assert.Equal(t, "application/json", body.Header.Get("Content-Type"))
+		assert.Equal(t, "[masked]", body.Header.Get("X-Subject"))
+		assert.Equal(t, "[masked]", body.Header.Get("Authorization"))
+	})
+
+	t.Run("case=empty payload is allowed without calling the remote", func(t *testing.T) {
+		res := preview(t, "empty")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var body authz.Preview
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+		assert.True(t, body.Skipped)
+		assert.Empty(t, body.Header)
+	})
+
+	t.Run("case=invalid payload is not echoed", func(t *testing.T) {
+		res := preview(t, "invalid")
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+		reason, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(reason), "is not a JSON text")
+		assert.NotContains(t, string(reason), "secret")
+	})
+
+	t.Run("case=unknown rule", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, preview(t, "unknown").StatusCode)
+	})
+
+	t.Run("case=authorizer without previews", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, preview(t, "allow").StatusCode)
+	})
+}
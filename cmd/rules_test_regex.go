@@ -0,0 +1,49 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ory/oathkeeper/driver/configuration"
+	"github.com/ory/oathkeeper/rule"
+	"github.com/ory/oathkeeper/x"
+	"github.com/ory/x/cmdx"
+	"github.com/ory/x/logrusx"
+)
+
+// testRegexCmd represents the test-regex command
+var testRegexCmd = &cobra.Command{
+	Use:   "test-regex <pattern> <input>",
+	Short: "Test a regexp rule pattern against a sample input",
+	Long: `Evaluates a pattern using the regexp matching strategy and prints whether the
+input matches as well as the positional and named capture groups as JSON. Patterns are
+evaluated with the access_rules.max_capture_groups and access_rules.auto_anchor settings
+of the configuration.
+
+Usage example:
+
+	oathkeeper rules test-regex 'https://mydomain.com/users/<[0-9]+>' https://mydomain.com/users/1234
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdx.ExactArgs(cmd, args, 2)
+
+		c, err := configuration.NewKoanfProvider(cmd.Context(), cmd.Flags(), logrusx.New("ORY Oathkeeper", x.Version))
+		cmdx.Must(err, "Unable to load the configuration: %s", err)
+
+		result, err := rule.EvaluateRegexp(args[0], args[1], c.AccessRuleMaxCaptureGroups(), c.AccessRuleAutoAnchor())
+		cmdx.Must(err, `Unable to evaluate pattern "%s": %s`, args[0], err)
+
+		e := json.NewEncoder(cmd.OutOrStdout())
+		e.SetIndent("", "  ")
+		err = e.Encode(result)
+		cmdx.Must(err, "Unable to encode result to JSON: %s", err)
+	},
+}
+
+func init() {
+	rulesCmd.AddCommand(testRegexCmd)
+}
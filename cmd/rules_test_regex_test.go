@@ -0,0 +1,52 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/oathkeeper/rule"
+	"github.com/ory/x/cmdx"
+)
+
+func TestRulesTestRegex(t *testing.T) {
+	cmd := cmdx.CommandExecuter{
+		New: func() *cobra.Command {
+			cp := *RootCmd
+			return &cp
+		},
+	}
+	evaluate := func(t *testing.T, pattern, input string) *rule.RegexpEvaluation {
+		stdOut, stdErr, err := cmd.Exec(nil, "rules", "test-regex", pattern, input)
+		require.NoError(t, err, stdErr)
+
+		var result rule.RegexpEvaluation
+		require.NoError(t, json.Unmarshal([]byte(stdOut), &result), stdOut)
+		return &result
+	}
+
+	t.Run("case=prints the captures", func(t *testing.T) {
+		result := evaluate(t, "https://mydomain.com/<(?P<resource>users|groups)>/<[0-9]+>", "https://mydomain.com/users/1234")
+		assert.True(t, result.Matches)
+		assert.ElementsMatch(t, []string{"users", "users", "1234"}, result.Submatches)
+		assert.Equal(t, map[string]string{"resource": "users"}, result.NamedSubmatches)
+	})
+
+	t.Run("case=no match", func(t *testing.T) {
+		result := evaluate(t, "https://mydomain.com/users/<[0-9]+>", "https://mydomain.com/groups/1234")
+		assert.False(t, result.Matches)
+	})
+
+	t.Run("case=uses the configured anchoring", func(t *testing.T) {
+		assert.True(t, evaluate(t, "https://mydomain.com/users/<[0-9]+>", "https://mydomain.com/users/1234\n").Matches)
+
+		t.Setenv("ACCESS_RULES_AUTO_ANCHOR", "true")
+		assert.False(t, evaluate(t, "https://mydomain.com/users/<[0-9]+>", "https://mydomain.com/users/1234\n").Matches)
+	})
+}
@@ -109,6 +109,9 @@ func runAPI(d driver.Driver, n *negroni.Negroni, logger *logrusx.Logger, prom *m
 	return func() {
 		router := x.NewAPIRouter()
 		d.Registry().RuleHandler().SetRoutes(router)
+		if d.Configuration().APIRulePreviewEnabled() {
+			d.Registry().RuleHandler().SetPreviewRoutes(router)
+		}
 		d.Registry().HealthHandler().SetHealthRoutes(router.Router, true)
 		d.Registry().CredentialHandler().SetRoutes(router)
 
@@ -286,5 +289,11 @@ func RunServe(version, build, date string) func(cmd *cobra.Command, args []strin
 			}(t)
 		}
 		wg.Wait()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := d.Registry().ShutdownPipelineAuthorizers(ctx); err != nil {
+			logger.WithError(err).Errorf("Unable to shut down authorizers")
+		}
 	}
 }
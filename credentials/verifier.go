@@ -29,6 +29,7 @@ type ValidationContext struct {
 	Issuers       []string
 	Audiences     []string
 	ScopeStrategy fosite.ScopeStrategy
+	ScopePrefix   string
 	Scope         []string
 	KeyURLs       []url.URL
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/ory/fosite"
 	"github.com/ory/herodot"
 	"github.com/ory/oathkeeper/helper"
+	"github.com/ory/oathkeeper/x/scopex"
 	"github.com/ory/x/jwtx"
 	"github.com/ory/x/stringslice"
 	"github.com/ory/x/stringsx"
@@ -123,7 +124,8 @@ func (v *VerifierDefault) Verify(
 	if r.ScopeStrategy != nil {
 		for _, sc := range r.Scope {
 			if !r.ScopeStrategy(s, sc) {
-				return nil, herodot.ErrUnauthorized.WithReasonf(`JSON Web Token is missing required scope "%s".`, sc)
+				return nil, herodot.ErrUnauthorized.WithReasonf(`JSON Web Token is missing required scope "%s".`, sc).
+					WithDetail("required_scope", scopex.RequiredScope(r.ScopePrefix, sc, r.ScopeStrategy))
 			}
 		}
 	} else {
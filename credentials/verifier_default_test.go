@@ -16,7 +16,10 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/ory/fosite"
+	"github.com/ory/herodot"
+
 	"github.com/ory/oathkeeper/x"
+	"github.com/ory/oathkeeper/x/scopex"
 )
 
 func TestVerifierDefault(t *testing.T) {
@@ -312,6 +315,42 @@ func TestVerifierDefault(t *testing.T) {
 	}
 }
 
+func TestVerifierDefaultRequiredScope(t *testing.T) {
+	signer := NewSignerDefault(newDefaultSignerMockRegistry())
+	verifier := NewVerifierDefault(newDefaultSignerMockRegistry())
+
+	token, err := signer.Sign(context.Background(), x.ParseURLOrPanic("file://../test/stub/jwks-hs.json"), jwt.MapClaims{
+		"sub":   "sub",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "api:users.read",
+	})
+	require.NoError(t, err)
+
+	for k, tc := range []struct {
+		prefix   string
+		strategy fosite.ScopeStrategy
+		expect   string
+	}{
+		{strategy: fosite.WildcardScopeStrategy, expect: "users.write.own"},
+		{strategy: fosite.HierarchicScopeStrategy, expect: "users.write.own"},
+		{prefix: "api:", strategy: scopex.WithScopePrefix("api:", fosite.WildcardScopeStrategy), expect: "api:users.write.own"},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			_, err := verifier.Verify(context.Background(), token, &ValidationContext{
+				Algorithms:    []string{"HS256"},
+				KeyURLs:       []url.URL{*x.ParseURLOrPanic("file://../test/stub/jwks-hs.json")},
+				Scope:         []string{"users.write.own"},
+				ScopeStrategy: tc.strategy,
+				ScopePrefix:   tc.prefix,
+			})
+
+			var herr *herodot.DefaultError
+			require.ErrorAs(t, err, &herr)
+			assert.Equal(t, tc.expect, herr.DetailsField["required_scope"])
+		})
+	}
+}
+
 func TestScope(t *testing.T) {
 	for k, tc := range []struct {
 		i  map[string]interface{}
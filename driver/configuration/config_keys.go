@@ -17,14 +17,19 @@ const (
 	APIReadTimeout                      Key = "serve.api.timeout.read"
 	APIWriteTimeout                     Key = "serve.api.timeout.write"
 	APIIdleTimeout                      Key = "serve.api.timeout.idle"
+	APIRulePreviewEnabled               Key = "serve.api.rule_preview.enabled"
 	PrometheusServeAddressHost          Key = "serve.prometheus.host"
 	PrometheusServeAddressPort          Key = "serve.prometheus.port"
 	PrometheusServeMetricsPath          Key = "serve.prometheus.metrics_path"
 	PrometheusServeMetricsNamePrefix    Key = "serve.prometheus.metric_name_prefix"
 	PrometheusServeHideRequestPaths     Key = "serve.prometheus.hide_request_paths"
 	PrometheusServeCollapseRequestPaths Key = "serve.prometheus.collapse_request_paths"
+	PrometheusServeTemplateDurations    Key = "serve.prometheus.template_durations"
 	AccessRuleRepositories              Key = "access_rules.repositories"
 	AccessRuleMatchingStrategy          Key = "access_rules.matching_strategy"
+	AccessRuleMaxCaptureGroups          Key = "access_rules.max_capture_groups"
+	AccessRuleAutoAnchor                Key = "access_rules.auto_anchor"
+	HTTPClientProfiles                  Key = "http_client_profiles"
 )
 
 // Authorizers
@@ -34,6 +39,8 @@ const (
 	AuthorizerKetoEngineACPORYIsEnabled Key = "authorizers.keto_engine_acp_ory.enabled"
 	AuthorizerRemoteIsEnabled           Key = "authorizers.remote.enabled"
 	AuthorizerRemoteJSONIsEnabled       Key = "authorizers.remote_json.enabled"
+	AuthorizerRemoteJSONPayloadDirs     Key = "authorizers.remote_json.payload_directories"
+	AuthorizerDefaultDecision           Key = "authorizers.default_decision"
 )
 
 // Mutators
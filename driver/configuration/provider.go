@@ -31,6 +31,13 @@ const (
 	DefaultMatchingStrategy                  = Regexp
 )
 
+// ScopeStrategies are the names of the scope strategies ToScopeStrategy accepts.
+var ScopeStrategies = []string{"hierarchic", "exact", "wildcard", "none"}
+
+// DefaultAccessRuleMaxCaptureGroups is the maximum number of capture groups a regexp rule pattern may
+// declare if access_rules.max_capture_groups is not set.
+const DefaultAccessRuleMaxCaptureGroups = 256
+
 type Provider interface {
 	Get(k Key) interface{}
 	String(k Key) string
@@ -60,6 +67,8 @@ type Provider interface {
 
 	AccessRuleRepositories() []url.URL
 	AccessRuleMatchingStrategy() MatchingStrategy
+	AccessRuleMaxCaptureGroups() int
+	AccessRuleAutoAnchor() bool
 
 	ProxyServeAddress() string
 	APIServeAddress() string
@@ -69,6 +78,7 @@ type Provider interface {
 	PrometheusMetricsNamePrefix() string
 	PrometheusHideRequestPaths() bool
 	PrometheusCollapseRequestPaths() bool
+	PrometheusTemplateDurations() bool
 
 	ToScopeStrategy(value string, key string) fosite.ScopeStrategy
 	ParseURLs(sources []string) ([]url.URL, error)
@@ -78,6 +88,9 @@ type Provider interface {
 	TracingConfig() *otelx.Config
 
 	TLSConfig(daemon string) *TLSConfig
+	APIRulePreviewEnabled() bool
+	HTTPClientProfile(name string) (*HTTPClientProfile, error)
+	AuthorizerRemoteJSONPayloadDirectories() []string
 
 	SetForTest(t testing.TB, key string, value interface{})
 }
@@ -97,6 +110,7 @@ type ProviderAuthenticators interface {
 type ProviderAuthorizers interface {
 	AuthorizerConfig(id string, overrides json.RawMessage, destination interface{}) error
 	AuthorizerIsEnabled(id string) bool
+	AuthorizerDefaultDecision() string
 }
 
 type ProviderMutators interface {
@@ -34,6 +34,7 @@ import (
 
 	schema "github.com/ory/oathkeeper/spec"
 	"github.com/ory/oathkeeper/x"
+	"github.com/ory/oathkeeper/x/scopex"
 )
 
 type (
@@ -174,6 +175,16 @@ func (v *KoanfProvider) AccessRuleMatchingStrategy() MatchingStrategy {
 	return MatchingStrategy(v.source.String(AccessRuleMatchingStrategy))
 }
 
+// AccessRuleMaxCaptureGroups returns the maximum number of capture groups a regexp rule pattern may declare.
+func (v *KoanfProvider) AccessRuleMaxCaptureGroups() int {
+	return v.source.IntF(AccessRuleMaxCaptureGroups, DefaultAccessRuleMaxCaptureGroups)
+}
+
+// AccessRuleAutoAnchor returns whether regexp rule patterns are anchored to the start and end of the input.
+func (v *KoanfProvider) AccessRuleAutoAnchor() bool {
+	return v.source.Bool(AccessRuleAutoAnchor)
+}
+
 func (v *KoanfProvider) CORSEnabled(iface string) bool {
 	_, enabled := v.CORS(iface)
 	return enabled
@@ -207,6 +218,11 @@ func (v *KoanfProvider) ProxyServeAddress() string {
 	)
 }
 
+// APIRulePreviewEnabled returns whether the API serves previews of the requests authorizers would send.
+func (v *KoanfProvider) APIRulePreviewEnabled() bool {
+	return v.source.Bool(APIRulePreviewEnabled)
+}
+
 func (v *KoanfProvider) APIReadTimeout() time.Duration {
 	return v.source.DurationF(APIReadTimeout, 5*time.Second)
 }
@@ -247,6 +263,11 @@ func (v *KoanfProvider) PrometheusCollapseRequestPaths() bool {
 	return v.source.BoolF(PrometheusServeCollapseRequestPaths, true)
 }
 
+// PrometheusTemplateDurations returns whether the execution time of authorizer templates is recorded.
+func (v *KoanfProvider) PrometheusTemplateDurations() bool {
+	return v.source.Bool(PrometheusServeTemplateDurations)
+}
+
 func (v *KoanfProvider) ParseURLs(sources []string) ([]url.URL, error) {
 	r := make([]url.URL, len(sources))
 	for k, u := range sources {
@@ -286,6 +307,13 @@ func (v *KoanfProvider) ToScopeStrategy(value string, key string) fosite.ScopeSt
 	}
 }
 
+// RequiredScope returns the least privileged scope a token must be granted to satisfy needle.
+//
+// Deprecated: use scopex.RequiredScope instead.
+func RequiredScope(prefix, needle string, strategy fosite.ScopeStrategy) string {
+	return scopex.RequiredScope(prefix, needle, strategy)
+}
+
 func (v *KoanfProvider) pipelineIsEnabled(prefix, id string) bool {
 	return v.source.Bool(fmt.Sprintf("%s.%s.enabled", prefix, id))
 }
@@ -412,6 +440,12 @@ func (v *KoanfProvider) AuthorizerConfig(id string, override json.RawMessage, de
 	return v.PipelineConfig("authorizers", id, override, dest)
 }
 
+// AuthorizerDefaultDecision returns whether requests are allowed or denied if the authorizer of a rule
+// is not responsible for them. It defaults to "deny".
+func (v *KoanfProvider) AuthorizerDefaultDecision() string {
+	return v.source.StringF(AuthorizerDefaultDecision, "deny")
+}
+
 func (v *KoanfProvider) MutatorIsEnabled(id string) bool {
 	return v.pipelineIsEnabled("mutators", id)
 }
@@ -457,3 +491,41 @@ func (v *KoanfProvider) TLSConfig(daemon string) *TLSConfig {
 	}
 	return c
 }
+
+// HTTPClientProfile configures the HTTP client of handlers which reference it by name.
+type HTTPClientProfile struct {
+	Timeout             string                 `mapstructure:"timeout"`
+	MaxRetries          int                    `mapstructure:"max_retries"`
+	Retry               HTTPClientProfileRetry `mapstructure:"retry"`
+	TLS                 HTTPClientProfileTLS   `mapstructure:"tls"`
+	MaxIdleConns        int                    `mapstructure:"max_idle_conns"`
+	MaxIdleConnsPerHost int                    `mapstructure:"max_idle_conns_per_host"`
+	IdleConnTimeout     string                 `mapstructure:"idle_conn_timeout"`
+}
+type HTTPClientProfileRetry struct {
+	MaxDelay    string `mapstructure:"max_delay"`
+	GiveUpAfter string `mapstructure:"give_up_after"`
+}
+type HTTPClientProfileTLS struct {
+	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"`
+}
+
+// AuthorizerRemoteJSONPayloadDirectories returns the directories the remote_json authorizer may load
+// payload templates from.
+func (v *KoanfProvider) AuthorizerRemoteJSONPayloadDirectories() []string {
+	return v.source.Strings(AuthorizerRemoteJSONPayloadDirs)
+}
+
+// HTTPClientProfile returns the HTTP client profile configured under name.
+func (v *KoanfProvider) HTTPClientProfile(name string) (*HTTPClientProfile, error) {
+	key := HTTPClientProfiles + "." + name
+	if !v.source.Exists(key) {
+		return nil, errors.Errorf(`HTTP client profile "%s" is not configured`, name)
+	}
+
+	c := new(HTTPClientProfile)
+	if err := v.source.Unmarshal(key, c); err != nil {
+		return nil, errors.Wrapf(err, `unable to decode HTTP client profile "%s"`, name)
+	}
+	return c, nil
+}
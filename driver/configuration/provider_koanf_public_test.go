@@ -29,6 +29,14 @@ import (
 	"github.com/ory/x/otelx"
 )
 
+type remoteJSONDependencies struct {
+	x.TestLoggerProvider
+}
+
+func (*remoteJSONDependencies) RemoteJSONMetrics() *authz.RemoteJSONMetrics {
+	return authz.NewRemoteJSONMetrics("")
+}
+
 func setup(t *testing.T) *configuration.KoanfProvider {
 	p, err := configuration.NewKoanfProvider(
 		context.Background(),
@@ -336,8 +344,7 @@ func TestKoanfProvider(t *testing.T) {
 		})
 
 		t.Run("authorizer=remote_json", func(t *testing.T) {
-			l := logrusx.New("", "")
-			a := authz.NewAuthorizerRemoteJSON(p, otelx.NewNoop(l, p.TracingConfig()))
+			a := authz.NewAuthorizerRemoteJSON(p, new(remoteJSONDependencies))
 			assert.True(t, p.AuthorizerIsEnabled(a.GetID()))
 			require.NoError(t, a.Validate(nil))
 
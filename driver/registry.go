@@ -4,6 +4,8 @@
 package driver
 
 import (
+	"context"
+
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/ory/x/logrusx"
@@ -43,6 +45,9 @@ type Registry interface {
 	Proxy() *proxy.Proxy
 	Tracer() trace.Tracer
 
+	ShutdownPipelineAuthorizers(ctx context.Context) error
+	RemoteJSONMetrics() *authz.RemoteJSONMetrics
+
 	authn.Registry
 	authz.Registry
 	mutate.Registry
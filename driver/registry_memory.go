@@ -5,7 +5,10 @@ package driver
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
 	"sync"
+	"time"
 
 	"go.opentelemetry.io/otel/trace"
 
@@ -16,6 +19,7 @@ import (
 
 	"github.com/ory/x/logrusx"
 	"github.com/ory/x/otelx"
+	"github.com/ory/x/watcherx"
 
 	"github.com/pkg/errors"
 
@@ -59,10 +63,16 @@ type RegistryMemory struct {
 	proxyProxy          *proxy.Proxy
 	ruleFetcher         rule.Fetcher
 
+	remoteJSONMetrics     *authz.RemoteJSONMetrics
+	remoteJSONMetricsOnce sync.Once
+
 	authenticators map[string]authn.Authenticator
 	authorizers    map[string]authz.Authorizer
 	mutators       map[string]mutate.Mutator
 	errors         map[string]pe.Handler
+
+	// authorizerConfigs holds the encoded configuration each authorizer was created with.
+	authorizerConfigs map[string]string
 }
 
 func (r *RegistryMemory) Init() {
@@ -71,6 +81,48 @@ func (r *RegistryMemory) Init() {
 		r.Logger().WithError(err).Fatal("Access rule watcher could not be initialized.")
 	}
 	_ = r.RuleRepository()
+	r.c.AddWatcher(r.replacePipelineAuthorizers)
+}
+
+// replacePipelineAuthorizers replaces the authorizers whose configuration changed with new ones and shuts
+// down the replaced ones. Authorizers whose configuration did not change keep their state, such as cached
+// decisions, but are reloaded.
+func (r *RegistryMemory) replacePipelineAuthorizers(_ watcherx.Event, err error) {
+	if err != nil {
+		return
+	}
+
+	var replaced []authz.Authorizer
+	r.Lock()
+	if r.authorizers != nil {
+		for _, a := range r.newPipelineAuthorizers() {
+			id := a.GetID()
+			if config := r.pipelineAuthorizerConfig(id); config != r.authorizerConfigs[id] {
+				replaced = append(replaced, r.authorizers[id])
+				r.authorizers[id] = a
+				r.authorizerConfigs[id] = config
+			} else if reloader, ok := r.authorizers[id].(authz.Reloader); ok {
+				reloader.Reload()
+			}
+		}
+	}
+	r.Unlock()
+
+	if len(replaced) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownPipelineAuthorizers(ctx, replaced); err != nil {
+		r.Logger().WithError(err).Error("Unable to shut down replaced authorizers.")
+	}
+}
+
+// pipelineAuthorizerConfig returns the encoded configuration of the authorizer with the given ID.
+func (r *RegistryMemory) pipelineAuthorizerConfig(id string) string {
+	config, _ := json.Marshal(r.c.Get(configuration.Key("authorizers." + id)))
+	return string(config)
 }
 
 func (r *RegistryMemory) RuleFetcher() rule.Fetcher {
@@ -139,9 +191,32 @@ func (r *RegistryMemory) CredentialHandler() *api.CredentialsHandler {
 }
 
 func (r *RegistryMemory) HealthxReadyCheckers() healthx.ReadyCheckers {
-	return healthx.ReadyCheckers{
+	checkers := healthx.ReadyCheckers{
 		"rules_loaded": r.RuleRepository().ReadyChecker,
 	}
+
+	for _, id := range r.AvailablePipelineAuthorizers() {
+		if !r.c.AuthorizerIsEnabled(id) {
+			continue
+		}
+		a, err := r.PipelineAuthorizer(id)
+		if err != nil {
+			continue
+		}
+		if _, ok := a.(authz.Prober); !ok {
+			continue
+		}
+
+		checkers["authorizer_"+id] = func(req *http.Request) error {
+			a, err := r.PipelineAuthorizer(id)
+			if err != nil {
+				return err
+			}
+			return a.(authz.Prober).Probe(req.Context())
+		}
+	}
+
+	return checkers
 }
 
 func (r *RegistryMemory) HealthHandler() *healthx.Handler {
@@ -311,6 +386,37 @@ func (r *RegistryMemory) PipelineAuthorizer(id string) (authz.Authorizer, error)
 	return a, nil
 }
 
+// ShutdownPipelineAuthorizers shuts down all authorizers which own resources and discards them. Authorizers
+// are recreated on next use. The lock is not held while shutting down, so that requests do not wait for the
+// discarded authorizers to finish their queued work.
+func (r *RegistryMemory) ShutdownPipelineAuthorizers(ctx context.Context) error {
+	r.Lock()
+	authorizers := r.authorizers
+	r.authorizers = nil
+	r.authorizerConfigs = nil
+	r.Unlock()
+
+	replaced := make([]authz.Authorizer, 0, len(authorizers))
+	for _, a := range authorizers {
+		replaced = append(replaced, a)
+	}
+	return shutdownPipelineAuthorizers(ctx, replaced)
+}
+
+// shutdownPipelineAuthorizers shuts down the given authorizers which own resources.
+func shutdownPipelineAuthorizers(ctx context.Context, authorizers []authz.Authorizer) error {
+	var err error
+	for _, a := range authorizers {
+		if s, ok := a.(authz.Shutdowner); ok {
+			if shutdownErr := s.Shutdown(ctx); shutdownErr != nil && err == nil {
+				err = errors.Wrapf(shutdownErr, `unable to shut down authorizer "%s"`, a.GetID())
+			}
+		}
+	}
+
+	return err
+}
+
 func (r *RegistryMemory) AvailablePipelineMutators() (available []string) {
 	r.prepareMutators()
 	r.RLock()
@@ -377,21 +483,35 @@ func (r *RegistryMemory) prepareAuthz() {
 	r.Lock()
 	defer r.Unlock()
 	if r.authorizers == nil {
-		interim := []authz.Authorizer{
-			authz.NewAuthorizerAllow(r.c),
-			authz.NewAuthorizerDeny(r.c),
-			authz.NewAuthorizerKetoEngineACPORY(r.c, r),
-			authz.NewAuthorizerRemote(r.c, r),
-			authz.NewAuthorizerRemoteJSON(r.c, r),
-		}
-
 		r.authorizers = map[string]authz.Authorizer{}
-		for _, a := range interim {
+		r.authorizerConfigs = map[string]string{}
+		for _, a := range r.newPipelineAuthorizers() {
 			r.authorizers[a.GetID()] = a
+			r.authorizerConfigs[a.GetID()] = r.pipelineAuthorizerConfig(a.GetID())
 		}
 	}
 }
 
+func (r *RegistryMemory) newPipelineAuthorizers() []authz.Authorizer {
+	return []authz.Authorizer{
+		authz.NewAuthorizerAllow(r.c),
+		authz.NewAuthorizerDeny(r.c),
+		authz.NewAuthorizerKetoEngineACPORY(r.c, r),
+		authz.NewAuthorizerRemote(r.c, r),
+		authz.NewAuthorizerRemoteJSON(r.c, r),
+	}
+}
+
+// RemoteJSONMetrics returns the Prometheus collectors of the remote_json authorizer. They are created once
+// with the configured metric name prefix and shared by all remote_json authorizers, including the ones
+// rebuilt on configuration changes.
+func (r *RegistryMemory) RemoteJSONMetrics() *authz.RemoteJSONMetrics {
+	r.remoteJSONMetricsOnce.Do(func() {
+		r.remoteJSONMetrics = authz.NewRemoteJSONMetrics(r.c.PrometheusMetricsNamePrefix())
+	})
+	return r.remoteJSONMetrics
+}
+
 func (r *RegistryMemory) prepareMutators() {
 	r.Lock()
 	defer r.Unlock()
@@ -5,12 +5,16 @@ package driver
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/ory/oathkeeper/driver/configuration"
+	"github.com/ory/x/configx"
 	"github.com/ory/x/logrusx"
 )
 
@@ -50,3 +54,80 @@ func TestRegistryMemoryPipelineAuthorizer(t *testing.T) {
 		})
 	}
 }
+
+func TestRegistryMemoryShutdownPipelineAuthorizers(t *testing.T) {
+	c, err := configuration.NewKoanfProvider(context.Background(), nil, logrusx.New("", ""))
+	require.NoError(t, err)
+	r := NewRegistry(c)
+
+	before, err := r.PipelineAuthorizer("remote_json")
+	require.NoError(t, err)
+
+	require.NoError(t, r.ShutdownPipelineAuthorizers(context.Background()))
+
+	after, err := r.PipelineAuthorizer("remote_json")
+	require.NoError(t, err)
+	assert.NotSame(t, before, after)
+	assert.ElementsMatch(t, r.AvailablePipelineAuthorizers(), []string{"allow", "deny", "keto_engine_acp_ory", "remote", "remote_json"})
+}
+
+func TestRegistryMemoryReplacePipelineAuthorizers(t *testing.T) {
+	c, err := configuration.NewKoanfProvider(context.Background(), nil, logrusx.New("", ""))
+	require.NoError(t, err)
+	r := NewRegistryMemory().WithConfig(c).(*RegistryMemory)
+
+	before, err := r.PipelineAuthorizer("remote_json")
+	require.NoError(t, err)
+
+	r.replacePipelineAuthorizers(nil, errors.New("unable to read the configuration"))
+	kept, err := r.PipelineAuthorizer("remote_json")
+	require.NoError(t, err)
+	assert.Same(t, before, kept, "authorizers are kept if the configuration could not be read")
+
+	c.SetForTest(t, configuration.AuthorizerAllowIsEnabled, true)
+	r.replacePipelineAuthorizers(nil, nil)
+	kept, err = r.PipelineAuthorizer("remote_json")
+	require.NoError(t, err)
+	assert.Same(t, before, kept, "authorizers are kept if only the configuration of other authorizers changed")
+
+	c.SetForTest(t, "authorizers.remote_json.config.remote", "http://localhost/authorize")
+	r.replacePipelineAuthorizers(nil, nil)
+	after, err := r.PipelineAuthorizer("remote_json")
+	require.NoError(t, err)
+	assert.NotSame(t, before, after, "authorizers are recreated after their configuration changed")
+}
+
+func TestRegistryMemoryHealthxReadyCheckers(t *testing.T) {
+	t.Run("case=remote_json disabled", func(t *testing.T) {
+		c, err := configuration.NewKoanfProvider(context.Background(), nil, logrusx.New("", ""))
+		require.NoError(t, err)
+		r := NewRegistry(c)
+		assert.NotContains(t, r.HealthxReadyCheckers(), "authorizer_remote_json")
+	})
+
+	t.Run("case=remote_json enabled", func(t *testing.T) {
+		status := http.StatusOK
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(status)
+		}))
+		defer server.Close()
+
+		c, err := configuration.NewKoanfProvider(context.Background(), nil, logrusx.New("", ""),
+			configx.WithValue("authorizers.remote_json.enabled", true),
+			configx.WithValue("authorizers.remote_json.config.remote", server.URL),
+			configx.WithValue("authorizers.remote_json.config.payload", "{}"),
+			configx.WithValue("authorizers.remote_json.config.health_check.endpoint", server.URL),
+		)
+		require.NoError(t, err)
+		r := NewRegistry(c)
+
+		checkers := r.HealthxReadyCheckers()
+		require.Contains(t, checkers, "authorizer_remote_json")
+
+		req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+		require.NoError(t, checkers["authorizer_remote_json"](req))
+
+		status = http.StatusServiceUnavailable
+		require.Error(t, checkers["authorizer_remote_json"](req))
+	})
+}
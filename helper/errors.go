@@ -56,6 +56,11 @@ var (
 		CodeField:   http.StatusBadRequest,
 		StatusField: http.StatusText(http.StatusBadRequest),
 	}
+	ErrTooManyRequests = &herodot.DefaultError{
+		ErrorField:  "The request was denied for now, try again later",
+		CodeField:   http.StatusTooManyRequests,
+		StatusField: http.StatusText(http.StatusTooManyRequests),
+	}
 	ErrUpstreamServiceNotAvailable = &herodot.DefaultError{
 		ErrorField:  "The upstream service is not available",
 		CodeField:   http.StatusServiceUnavailable,
@@ -77,3 +82,22 @@ var (
 		StatusField: http.StatusText(http.StatusNotFound),
 	}
 )
+
+// ErrWithHeader wraps an error with HTTP headers which should be sent along with the error response.
+type ErrWithHeader struct {
+	err    error
+	header http.Header
+}
+
+// WithHeader wraps err with HTTP headers which should be sent along with the error response.
+func WithHeader(err error, header http.Header) error {
+	if err == nil {
+		return nil
+	}
+	return &ErrWithHeader{err: err, header: header}
+}
+
+func (e *ErrWithHeader) Error() string       { return e.err.Error() }
+func (e *ErrWithHeader) Cause() error        { return e.err }
+func (e *ErrWithHeader) Unwrap() error       { return e.err }
+func (e *ErrWithHeader) Header() http.Header { return e.header }
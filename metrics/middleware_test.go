@@ -180,6 +180,28 @@ serve:
 	}
 }
 
+func TestConfigurablePrometheusRemoteJSONMetrics(t *testing.T) {
+	logger := logrusx.New("ORY Oathkeeper", "1")
+	d := driver.NewDefaultDriver(logger, "1", "test", time.Now().String(), nil,
+		configx.WithConfigFiles(x.WriteFile(t, `
+serve:
+  prometheus:
+    metric_name_prefix: http_
+`)),
+	)
+	promRepo := NewConfigurablePrometheusRepository(d, logger)
+
+	// The remote_json authorizer counts into the collectors which were registered.
+	d.Registry().RemoteJSONMetrics().AsyncDroppedTotal.Inc()
+	if err := testutil.GatherAndCompare(promRepo.Registry, strings.NewReader(`
+# HELP http_remote_json_async_dropped_total Total number of asynchronous remote_json calls dropped because the queue was full
+# TYPE http_remote_json_async_dropped_total counter
+http_remote_json_async_dropped_total 1
+`), "http_remote_json_async_dropped_total"); err != nil {
+		t.Fatal(err)
+	}
+}
+
 var requestURIParams = []struct {
 	name         string
 	originalPath string
@@ -65,17 +65,17 @@ func NewConfigurablePrometheusRepository(d driver.Driver, logger *logrusx.Logger
 		},
 		[]string{"service", "method", "request", "status_code"},
 	)
-	return NewPrometheusRepository(logger)
+	return NewPrometheusRepository(logger, d.Registry().RemoteJSONMetrics().Collectors()...)
 }
 
-// NewPrometheusRepository creates a new prometheus repository
-func NewPrometheusRepository(logger *logrusx.Logger) *PrometheusRepository {
-	m := []prometheus.Collector{
+// NewPrometheusRepository creates a new prometheus repository which also registers the given collectors
+func NewPrometheusRepository(logger *logrusx.Logger, collectors ...prometheus.Collector) *PrometheusRepository {
+	m := append([]prometheus.Collector{
 		prometheus.NewGoCollector(),                                       //nolint:staticcheck // compatible with current deps
 		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}), //nolint:staticcheck // compatible with current deps
 		RequestTotal,
 		HistogramRequestDuration,
-	}
+	}, collectors...)
 
 	r := prometheus.NewRegistry()
 
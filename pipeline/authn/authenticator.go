@@ -7,8 +7,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
 
 	"github.com/ory/herodot"
 
@@ -54,6 +56,7 @@ type MatchContext struct {
 	URL                 *url.URL    `json:"url"`
 	Method              string      `json:"method"`
 	Header              http.Header `json:"header"`
+	Trailer             http.Header `json:"trailer,omitempty"`
 }
 
 type AuthenticatorForwardConfig interface {
@@ -76,3 +79,29 @@ func (a *AuthenticationSession) SetHeader(key, val string) {
 func (a *AuthenticationSession) Copy() *AuthenticationSession {
 	return copystructure.Must(copystructure.Copy(a)).(*AuthenticationSession)
 }
+
+// ExtractScopes returns the scopes found at the GJSON path in the JSON representation of the session, for
+// example "extra.scope". Following OAuth 2.0 conventions, scopes may be given as a space-delimited string
+// or as an array of strings. If the path does not exist or holds another type, no scopes are returned.
+func ExtractScopes(session *AuthenticationSession, path string) []string {
+	encoded, err := json.Marshal(session)
+	if err != nil {
+		return []string{}
+	}
+
+	result := gjson.GetBytes(encoded, path)
+	switch {
+	case result.IsArray():
+		scopes := []string{}
+		for _, scope := range result.Array() {
+			if scope.Type == gjson.String {
+				scopes = append(scopes, scope.String())
+			}
+		}
+		return scopes
+	case result.Type == gjson.String:
+		return strings.Fields(result.String())
+	default:
+		return []string{}
+	}
+}
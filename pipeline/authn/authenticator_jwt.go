@@ -18,6 +18,7 @@ import (
 	"github.com/ory/oathkeeper/driver/configuration"
 	"github.com/ory/oathkeeper/helper"
 	"github.com/ory/oathkeeper/pipeline"
+	"github.com/ory/oathkeeper/x/scopex"
 	"github.com/ory/x/jwtx"
 	"github.com/ory/x/otelx"
 )
@@ -34,6 +35,7 @@ type AuthenticatorOAuth2JWTConfiguration struct {
 	AllowedAlgorithms   []string                    `json:"allowed_algorithms"`
 	JWKSURLs            []string                    `json:"jwks_urls"`
 	ScopeStrategy       string                      `json:"scope_strategy"`
+	ScopePrefix         string                      `json:"scope_prefix"`
 	BearerTokenLocation *helper.BearerTokenLocation `json:"token_from"`
 }
 
@@ -110,12 +112,17 @@ func (a *AuthenticatorJWT) Authenticate(r *http.Request, session *Authentication
 		Scope:         cf.Scope,
 		Issuers:       cf.Issuers,
 		Audiences:     cf.Audience,
-		ScopeStrategy: a.c.ToScopeStrategy(cf.ScopeStrategy, "authenticators.jwt.Config.scope_strategy"),
+		ScopeStrategy: scopex.WithScopePrefix(cf.ScopePrefix, a.c.ToScopeStrategy(cf.ScopeStrategy, "authenticators.jwt.Config.scope_strategy")),
+		ScopePrefix:   cf.ScopePrefix,
 	})
 	if err != nil {
 		de := herodot.ToDefaultError(err, "")
 		r := fmt.Sprintf("%+v", de)
-		return a.tryEnrichResultErr(token, helper.ErrUnauthorized.WithReason(r).WithTrace(err))
+		result := helper.ErrUnauthorized.WithReason(r).WithTrace(err)
+		if scope, ok := de.DetailsField["required_scope"]; ok {
+			result = result.WithDetail("required_scope", scope)
+		}
+		return a.tryEnrichResultErr(token, result)
 	}
 
 	claims, ok := pt.Claims.(jwt.MapClaims)
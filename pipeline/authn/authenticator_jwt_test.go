@@ -157,6 +157,16 @@ func TestAuthenticatorJWT(t *testing.T) {
 				config:    `{"token_from": {"cookie": "biscuit"}}`,
 				expectErr: false,
 			},
+			{
+				d: "should fail because the JWT provided in a proper location (cookie) is expired",
+				r: &http.Request{Header: http.Header{"Cookie": []string{"biscuit=" + gen(keys[1], jwt.MapClaims{
+					"sub": "sub",
+					"exp": now.Add(-time.Hour).Unix(),
+				})}}},
+				config:     `{"token_from": {"cookie": "biscuit"}}`,
+				expectErr:  true,
+				expectCode: 401,
+			},
 			{
 				d: "should pass because JWT is valid",
 				r: &http.Request{Header: http.Header{"Authorization": []string{"bearer " + gen(keys[1], jwt.MapClaims{
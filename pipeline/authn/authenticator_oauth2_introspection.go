@@ -25,6 +25,7 @@ import (
 	"github.com/ory/oathkeeper/helper"
 	"github.com/ory/oathkeeper/pipeline"
 	"github.com/ory/oathkeeper/x/header"
+	"github.com/ory/oathkeeper/x/scopex"
 	"github.com/ory/x/httpx"
 	"github.com/ory/x/logrusx"
 	"github.com/ory/x/otelx"
@@ -37,6 +38,7 @@ type AuthenticatorOAuth2IntrospectionConfiguration struct {
 	Issuers                     []string                                              `json:"trusted_issuers"`
 	PreAuth                     *AuthenticatorOAuth2IntrospectionPreAuthConfiguration `json:"pre_authorization"`
 	ScopeStrategy               string                                                `json:"scope_strategy"`
+	ScopePrefix                 string                                                `json:"scope_prefix"`
 	IntrospectionURL            string                                                `json:"introspection_url"`
 	PreserveHost                bool                                                  `json:"preserve_host"`
 	BearerTokenLocation         *helper.BearerTokenLocation                           `json:"token_from"`
@@ -186,7 +188,7 @@ func (a *AuthenticatorOAuth2Introspection) Authenticate(r *http.Request, session
 		return errors.WithStack(ErrAuthenticatorNotResponsible)
 	}
 
-	ss := a.c.ToScopeStrategy(cf.ScopeStrategy, "authenticators.oauth2_introspection.config.scope_strategy")
+	ss := scopex.WithScopePrefix(cf.ScopePrefix, a.c.ToScopeStrategy(cf.ScopeStrategy, "authenticators.oauth2_introspection.config.scope_strategy"))
 
 	i := a.tokenFromCache(cf, token, ss)
 	inCache := i != nil
@@ -256,7 +258,8 @@ func (a *AuthenticatorOAuth2Introspection) Authenticate(r *http.Request, session
 	if ss != nil {
 		for _, scope := range cf.Scopes {
 			if !ss(strings.Split(i.Scope, " "), scope) {
-				return errors.WithStack(helper.ErrForbidden.WithReason(fmt.Sprintf("Scope %s was not granted", scope)))
+				return errors.WithStack(helper.ErrForbidden.WithReason(fmt.Sprintf("Scope %s was not granted", scope)).
+					WithDetail("required_scope", scopex.RequiredScope(cf.ScopePrefix, scope, ss)))
 			}
 		}
 	}
@@ -74,3 +74,38 @@ func TestCopy(t *testing.T) {
 	assert.NotEqual(original.MatchContext.RegexpCaptureGroups, copied.MatchContext.RegexpCaptureGroups)
 	assert.NotEqual(original.MatchContext.Method, copied.MatchContext.Method)
 }
+
+func TestExtractScopes(t *testing.T) {
+	t.Parallel()
+	session := &authn.AuthenticationSession{
+		Subject: "alice",
+		Extra: map[string]interface{}{
+			"scope":  "read.users  write.users",
+			"scp":    []string{"read.users", "write.users"},
+			"mixed":  []interface{}{"read.users", 1, "write.users"},
+			"nested": map[string]interface{}{"roles": []interface{}{"admin"}},
+			"empty":  "",
+			"number": 1,
+		},
+	}
+
+	for k, tc := range []struct {
+		path     string
+		expected []string
+	}{
+		{path: "extra.scope", expected: []string{"read.users", "write.users"}},
+		{path: "extra.scp", expected: []string{"read.users", "write.users"}},
+		{path: "extra.mixed", expected: []string{"read.users", "write.users"}},
+		{path: "extra.nested.roles", expected: []string{"admin"}},
+		{path: "extra.empty", expected: []string{}},
+		{path: "extra.number", expected: []string{}},
+		{path: "extra.missing", expected: []string{}},
+		{path: "subject", expected: []string{"alice"}},
+	} {
+		t.Run(fmt.Sprintf("case=%d/path=%s", k, tc.path), func(t *testing.T) {
+			assert.Equal(t, tc.expected, authn.ExtractScopes(session, tc.path))
+		})
+	}
+
+	assert.Equal(t, []string{}, authn.ExtractScopes(nil, "extra.scope"))
+}
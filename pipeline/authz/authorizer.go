@@ -4,6 +4,7 @@
 package authz
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -15,6 +16,10 @@ import (
 	"github.com/ory/oathkeeper/pipeline/authn"
 )
 
+// ErrAuthorizerNotResponsible is returned by authorizers which abstain from deciding on a request so that
+// another authorizer can decide instead.
+var ErrAuthorizerNotResponsible = errors.New("Authorizer not responsible")
+
 var ErrAuthorizerNotEnabled = herodot.DefaultError{
 	ErrorField:  "authorizer matching this route is misconfigured or disabled",
 	CodeField:   http.StatusInternalServerError,
@@ -38,3 +43,36 @@ type Authorizer interface {
 	GetID() string
 	Validate(config json.RawMessage) error
 }
+
+// Shutdowner is implemented by authorizers which own resources, such as connections or background
+// workers, that must be released once the authorizer is no longer used.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Reloader is implemented by authorizers which cache data that is not part of the configuration, such as
+// files the configuration refers to, and must drop it when the configuration is reloaded.
+type Reloader interface {
+	Reload()
+}
+
+// Prober is implemented by authorizers which depend on a remote service and can check whether it is
+// ready to answer requests.
+type Prober interface {
+	Probe(ctx context.Context) error
+}
+
+// Previewer is implemented by authorizers which can show the request they would send to a remote service
+// for a session without sending it.
+type Previewer interface {
+	Preview(session *authn.AuthenticationSession, config json.RawMessage, rule pipeline.Rule) (*Preview, error)
+}
+
+// Preview is the request an authorizer would send to a remote service. Values of rendered headers are
+// masked. Skipped is set if the authorizer would allow the request without calling the remote.
+type Preview struct {
+	Remote  string          `json:"remote,omitempty"`
+	Body    json.RawMessage `json:"body"`
+	Header  http.Header     `json:"header"`
+	Skipped bool            `json:"skipped,omitempty"`
+}
@@ -7,7 +7,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"text/template"
 	"time"
@@ -32,6 +31,8 @@ type AuthorizerRemoteConfiguration struct {
 	Headers                          map[string]string                   `json:"headers"`
 	ForwardResponseHeadersToUpstream []string                            `json:"forward_response_headers_to_upstream"`
 	Retry                            *AuthorizerRemoteRetryConfiguration `json:"retry"`
+	BodySpoolThreshold               int64                               `json:"body_spool_threshold"`
+	NormalizeAuthorization           bool                                `json:"normalize_authorization"`
 }
 
 type AuthorizerRemoteRetryConfiguration struct {
@@ -73,18 +74,15 @@ func (a *AuthorizerRemote) Authorize(r *http.Request, session *authn.Authenticat
 		return err
 	}
 
-	read, write := io.Pipe()
-	go func() {
-		err := pipeRequestBody(r, write)
-		write.CloseWithError(errors.Wrapf(err, `could not pipe request body in rule "%s"`, rl.GetID()))
-	}()
-
-	req, err := http.NewRequestWithContext(ctx, "POST", c.Remote, read)
+	req, err := http.NewRequestWithContext(ctx, "POST", c.Remote, streamRequestBody(r, c.BodySpoolThreshold, rl))
 	if err != nil {
 		return errors.WithStack(err)
 	}
 	req.Header.Add("Content-Type", r.Header.Get("Content-Type"))
 	authz := r.Header.Get("Authorization")
+	if c.NormalizeAuthorization {
+		authz = normalizeAuthorization(authz)
+	}
 	if authz != "" {
 		req.Header.Add("Authorization", authz)
 	}
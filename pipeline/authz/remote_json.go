@@ -5,19 +5,45 @@ package authz
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"crypto/sha256"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math/rand"
+	"net"
 	"net/http"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"text/template"
 	"time"
 
+	"github.com/cespare/xxhash/v2"
+	"github.com/dgraph-io/ristretto"
+	"github.com/hashicorp/go-retryablehttp"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
 
 	"github.com/ory/x/httpx"
+	"github.com/ory/x/logrusx"
 	"github.com/ory/x/otelx"
+	"github.com/ory/x/stringslice"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/ory/oathkeeper/driver/configuration"
 	"github.com/ory/oathkeeper/helper"
@@ -28,11 +54,98 @@ import (
 
 // AuthorizerRemoteJSONConfiguration represents a configuration for the remote_json authorizer.
 type AuthorizerRemoteJSONConfiguration struct {
-	Remote                           string                                  `json:"remote"`
-	Headers                          map[string]string                       `json:"headers"`
-	Payload                          string                                  `json:"payload"`
-	ForwardResponseHeadersToUpstream []string                                `json:"forward_response_headers_to_upstream"`
-	Retry                            *AuthorizerRemoteJSONRetryConfiguration `json:"retry"`
+	Remote                                   string                                            `json:"remote"`
+	RemoteHosts                              []string                                          `json:"remote_hosts"`
+	Headers                                  map[string]string                                 `json:"headers"`
+	Payload                                  string                                            `json:"payload"`
+	ForwardResponseHeadersToUpstream         []string                                          `json:"forward_response_headers_to_upstream"`
+	ForwardResponseHeadersToUpstreamByStatus map[int][]string                                  `json:"forward_response_headers_to_upstream_by_status,omitempty"`
+	Retry                                    *AuthorizerRemoteJSONRetryConfiguration           `json:"retry"`
+	StrictTemplates                          bool                                              `json:"strict_templates"`
+	DeadlineHeader                           string                                            `json:"deadline_header"`
+	Timeout                                  string                                            `json:"timeout"`
+	Methods                                  []string                                          `json:"methods"`
+	BypassOptions                            *bool                                             `json:"bypass_options,omitempty"`
+	BypassHead                               bool                                              `json:"bypass_head"`
+	UnixSocket                               string                                            `json:"unix_socket"`
+	TemplateCacheHash                        string                                            `json:"template_cache_hash"`
+	WWWAuthenticate                          string                                            `json:"www_authenticate"`
+	ResponseStatus                           *AuthorizerRemoteJSONResponseStatusConfiguration  `json:"response_status"`
+	HealthCheck                              *AuthorizerRemoteJSONHealthCheckConfiguration     `json:"health_check"`
+	DenialLogLevel                           string                                            `json:"denial_log_level"`
+	TemplateDelimiters                       *AuthorizerRemoteJSONTemplateDelimiters           `json:"template_delimiters"`
+	Remotes                                  []AuthorizerRemoteJSONEndpoint                    `json:"remotes"`
+	LoadBalance                              string                                            `json:"load_balance"`
+	Resolve                                  map[string]string                                 `json:"resolve"`
+	Async                                    bool                                              `json:"async"`
+	AsyncWorkers                             int                                               `json:"async_workers"`
+	AsyncQueueSize                           int                                               `json:"async_queue_size"`
+	ClientProfile                            string                                            `json:"client_profile"`
+	AbstainStatusCodes                       []int                                             `json:"abstain_status_codes"`
+	EmptyPayload                             string                                            `json:"empty_payload"`
+	MaxPayloadBytes                          int                                               `json:"max_payload_bytes"`
+	RetryableDenial                          *AuthorizerRemoteJSONRetryableDenialConfiguration `json:"retryable_denial"`
+	HeaderConditions                         map[string]string                                 `json:"header_conditions"`
+	TimeoutStatusCode                        int                                               `json:"timeout_status_code"`
+	RetryOnStatus                            []int                                             `json:"retry_on_status"`
+	NoRetryOnStatus                          []int                                             `json:"no_retry_on_status"`
+	RequestCompression                       string                                            `json:"request_compression"`
+	MethodPayloads                           map[string]string                                 `json:"method_payloads"`
+	HostHeader                               string                                            `json:"host_header"`
+	Method                                   string                                            `json:"method"`
+	PayloadQueryParameter                    string                                            `json:"payload_query_parameter"`
+	AcceptStatusCodes                        []int                                             `json:"accept_status_codes"`
+	ForbiddenStatusCodes                     []int                                             `json:"forbidden_status_codes"`
+	ForwardRequestHeaders                    []string                                          `json:"forward_request_headers"`
+	Cache                                    *AuthorizerRemoteJSONCacheConfiguration           `json:"cache"`
+	BodySpoolThreshold                       int64                                             `json:"body_spool_threshold"`
+	NormalizeAuthorization                   bool                                              `json:"normalize_authorization"`
+}
+
+// AuthorizerRemoteJSONEndpoint is one of several remote authorizers requests are distributed across.
+// Weight is only used by the weighted load balancing strategy.
+type AuthorizerRemoteJSONEndpoint struct {
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+// AuthorizerRemoteJSONTemplateDelimiters replaces the "{{" and "}}" action delimiters of all templates
+// of the authorizer.
+type AuthorizerRemoteJSONTemplateDelimiters struct {
+	Left  string `json:"left"`
+	Right string `json:"right"`
+}
+
+// AuthorizerRemoteJSONHealthCheckConfiguration configures how the readiness of the remote is checked.
+// Type is either "http" (the default) or "grpc". For gRPC, Service is the name of the service whose
+// status is checked using the gRPC health checking protocol.
+type AuthorizerRemoteJSONHealthCheckConfiguration struct {
+	Type     string `json:"type"`
+	Endpoint string `json:"endpoint"`
+	Service  string `json:"service"`
+}
+
+// AuthorizerRemoteJSONResponseStatusConfiguration configures where the response status of an allowing
+// remote is written to in the authentication session's extra field.
+type AuthorizerRemoteJSONResponseStatusConfiguration struct {
+	Key    string `json:"key"`
+	Header string `json:"header"`
+}
+
+// AuthorizerRemoteJSONRetryableDenialConfiguration configures which responses of the remote deny the
+// request only for now. Such denials are answered with 429 Too Many Requests and a Retry-After header taken
+// from the response's Retry-After header or, if that is missing, from RetryAfterPath in the JSON body.
+type AuthorizerRemoteJSONRetryableDenialConfiguration struct {
+	StatusCodes    []int  `json:"status_codes"`
+	RetryAfterPath string `json:"retry_after_path"`
+}
+
+// AuthorizerRemoteJSONCacheConfiguration configures the caching of decisions of the remote. Responses which
+// allow or deny a request are cached for TTL, keyed on the endpoints, the headers and the rendered payload of
+// the request to the remote. MaxCost is the maximum number of cached decisions.
+type AuthorizerRemoteJSONCacheConfiguration struct {
+	TTL     string `json:"ttl"`
+	MaxCost int    `json:"max_cost"`
 }
 
 type AuthorizerRemoteJSONRetryConfiguration struct {
@@ -40,119 +153,1402 @@ type AuthorizerRemoteJSONRetryConfiguration struct {
 	MaxWait string `json:"give_up_after"`
 }
 
-// PayloadTemplateID returns a string with which to associate the payload template.
-func (c *AuthorizerRemoteJSONConfiguration) PayloadTemplateID() string {
-	return fmt.Sprintf("%x", sha256.Sum256([]byte(c.Payload)))
+// PayloadTemplateID returns a string with which to associate the payload template. The hash algorithm
+// is selected by TemplateCacheHash and defaults to SHA-256.
+func (c *AuthorizerRemoteJSONConfiguration) PayloadTemplateID() string {
+	return c.templateID(c.Payload)
+}
+
+// templateID returns a string with which to associate a template by the hash of its content.
+func (c *AuthorizerRemoteJSONConfiguration) templateID(content string) string {
+	if c.TemplateCacheHash == "xxhash" {
+		return fmt.Sprintf("xxhash:%x", xxhash.Sum64String(content))
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+}
+
+// payloadFor returns the payload template for requests with the given method. Methods without a dedicated
+// template in MethodPayloads use Payload.
+func (c *AuthorizerRemoteJSONConfiguration) payloadFor(method string) string {
+	if payload, ok := c.MethodPayloads[strings.ToUpper(method)]; ok {
+		return payload
+	}
+	return c.Payload
+}
+
+// ResponseHeadersToForward returns the response headers which should be forwarded to the upstream
+// for the given response status code. Status codes without a dedicated list fall back to
+// ForwardResponseHeadersToUpstream.
+func (c *AuthorizerRemoteJSONConfiguration) ResponseHeadersToForward(statusCode int) []string {
+	if headers, ok := c.ForwardResponseHeadersToUpstreamByStatus[statusCode]; ok {
+		return headers
+	}
+	return c.ForwardResponseHeadersToUpstream
+}
+
+// BypassesMethod reports whether requests using the given method are allowed without calling the remote.
+// OPTIONS requests, such as CORS preflight requests, are bypassed unless BypassOptions is set to false.
+func (c *AuthorizerRemoteJSONConfiguration) BypassesMethod(method string) bool {
+	switch method {
+	case http.MethodOptions:
+		return c.BypassOptions == nil || *c.BypassOptions
+	case http.MethodHead:
+		return c.BypassHead
+	}
+	return false
+}
+
+// Endpoints returns the URLs of the remote authorizers in the order they are tried for a single request.
+// The first URL is selected by the load balancing strategy, the remaining ones follow in configured order
+// and are only used if the preceding ones can not be reached. n is the number of requests the strategy
+// has already distributed and rnd returns a random number in [0, max).
+func (c *AuthorizerRemoteJSONConfiguration) Endpoints(n uint64, rnd func(max int) int) []string {
+	if len(c.Remotes) == 0 {
+		return []string{c.Remote}
+	}
+
+	first := 0
+	switch c.LoadBalance {
+	case "round_robin":
+		first = int(n % uint64(len(c.Remotes)))
+	case "random":
+		first = rnd(len(c.Remotes))
+	case "weighted":
+		total := 0
+		for _, e := range c.Remotes {
+			total += e.weight()
+		}
+		pick := rnd(total)
+		for k, e := range c.Remotes {
+			if pick < e.weight() {
+				first = k
+				break
+			}
+			pick -= e.weight()
+		}
+	}
+
+	endpoints := make([]string, 0, len(c.Remotes))
+	endpoints = append(endpoints, c.Remotes[first].URL)
+	for k, e := range c.Remotes {
+		if k != first {
+			endpoints = append(endpoints, e.URL)
+		}
+	}
+	return endpoints
+}
+
+func (e AuthorizerRemoteJSONEndpoint) weight() int {
+	if e.Weight < 1 {
+		return 1
+	}
+	return e.Weight
+}
+
+// delims returns the action delimiters of the templates. Empty delimiters select the text/template
+// defaults.
+func (c *AuthorizerRemoteJSONConfiguration) delims() (left, right string) {
+	if c.TemplateDelimiters == nil {
+		return "", ""
+	}
+	return c.TemplateDelimiters.Left, c.TemplateDelimiters.Right
+}
+
+// actionDelims returns the delimiters of template actions like delims, but with text/template's defaults
+// in place of empty delimiters.
+func (c *AuthorizerRemoteJSONConfiguration) actionDelims() (left, right string) {
+	left, right = c.delims()
+	if left == "" {
+		left = "{{"
+	}
+	if right == "" {
+		right = "}}"
+	}
+	return left, right
+}
+
+// remoteTemplated returns whether Remote contains template actions and must be rendered for each request.
+func (c *AuthorizerRemoteJSONConfiguration) remoteTemplated() bool {
+	left, _ := c.actionDelims()
+	return strings.Contains(c.Remote, left)
+}
+
+// remoteOrigin returns the scheme and host of Remote if they precede its first template action.
+func (c *AuthorizerRemoteJSONConfiguration) remoteOrigin() (*url.URL, bool) {
+	left, _ := c.actionDelims()
+	static, _, _ := strings.Cut(c.Remote, left)
+	scheme, rest, ok := strings.Cut(static, "://")
+	if !ok {
+		return nil, false
+	}
+	end := strings.IndexAny(rest, "/?#")
+	if end < 0 {
+		return nil, false
+	}
+
+	origin, err := url.Parse(scheme + "://" + rest[:end])
+	if err != nil || origin.Host == "" {
+		return nil, false
+	}
+	return origin, true
+}
+
+// remoteAllowed reports whether a rendered templated remote may be called. Its host must be listed in
+// RemoteHosts, where entries starting with "*." match all subdomains. Without RemoteHosts, it must keep
+// the scheme and host of the template, so that session data cannot redirect calls to other hosts.
+func (c *AuthorizerRemoteJSONConfiguration) remoteAllowed(remote *url.URL) bool {
+	if len(c.RemoteHosts) == 0 {
+		origin, ok := c.remoteOrigin()
+		return ok && strings.EqualFold(remote.Scheme, origin.Scheme) && strings.EqualFold(remote.Host, origin.Host)
+	}
+
+	host := strings.ToLower(remote.Hostname())
+	for _, allowed := range c.RemoteHosts {
+		allowed = strings.ToLower(allowed)
+		if suffix, ok := strings.CutPrefix(allowed, "*"); ok && strings.HasPrefix(suffix, ".") {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultRemoteJSONTimeout is the time a call to the remote may take if the configuration does not set one.
+const defaultRemoteJSONTimeout = time.Minute
+
+// callTimeout returns the time a call to the remote, including its retries, may take at most.
+func (c *AuthorizerRemoteJSONConfiguration) callTimeout() time.Duration {
+	timeout, err := time.ParseDuration(c.Timeout)
+	if err != nil || timeout <= 0 {
+		return defaultRemoteJSONTimeout
+	}
+	return timeout
+}
+
+// denialLogLevel returns the level at which requests denied by the remote are logged. It defaults to info.
+func (c *AuthorizerRemoteJSONConfiguration) denialLogLevel() logrus.Level {
+	if c.DenialLogLevel == "debug" {
+		return logrus.DebugLevel
+	}
+	return logrus.InfoLevel
+}
+
+// abstains returns whether the remote authorizer abstains from deciding on a request by responding with code.
+func (c *AuthorizerRemoteJSONConfiguration) abstains(code int) bool {
+	for _, abstain := range c.AbstainStatusCodes {
+		if code == abstain {
+			return true
+		}
+	}
+	return false
+}
+
+// accepts returns whether the remote authorizer allows a request by responding with code.
+func (c *AuthorizerRemoteJSONConfiguration) accepts(code int) bool {
+	return slices.Contains(c.AcceptStatusCodes, code)
+}
+
+// forbids returns whether the remote authorizer denies a request by responding with code.
+func (c *AuthorizerRemoteJSONConfiguration) forbids(code int) bool {
+	return slices.Contains(c.ForbiddenStatusCodes, code)
+}
+
+// gzipPayload compresses the payload with gzip.
+func gzipPayload(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(payload); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// checkRetry extends the retry policy of the resilient client so that responses with a status code from
+// RetryOnStatus are retried and responses with a status code from NoRetryOnStatus are not.
+func (c *AuthorizerRemoteJSONConfiguration) checkRetry(ctx context.Context, res *http.Response, err error) (bool, error) {
+	if err == nil && ctx.Err() == nil {
+		if slices.Contains(c.NoRetryOnStatus, res.StatusCode) {
+			return false, nil
+		} else if slices.Contains(c.RetryOnStatus, res.StatusCode) {
+			return true, nil
+		}
+	}
+	return retryablehttp.DefaultRetryPolicy(ctx, res, err)
+}
+
+// unavailable classifies an error sending a request to the remote. Timeouts and remotes which cannot be
+// dialed are answered with TimeoutStatusCode to tell clients that the failure is transient. All other
+// errors, for example a remote which keeps answering with 5xx until the retries are used up, are returned
+// as they are and result in 500 Internal Server Error.
+func (c *AuthorizerRemoteJSONConfiguration) unavailable(err error) error {
+	base := helper.ErrUpstreamServiceNotAvailable
+	var netErr net.Error
+	var opErr *net.OpError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()):
+		base = helper.ErrUpstreamServiceTimeout
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+	default:
+		return errors.WithStack(err)
+	}
+
+	e := base.WithReason(errors.Cause(err).Error())
+	e.CodeField = c.TimeoutStatusCode
+	e.StatusField = http.StatusText(c.TimeoutStatusCode)
+	return errors.WithStack(e)
+}
+
+// RemoteJSONMetrics are the Prometheus collectors of the remote_json authorizer.
+type RemoteJSONMetrics struct {
+	// AsyncDroppedTotal counts asynchronous calls which were dropped because the queue was full.
+	AsyncDroppedTotal prometheus.Counter
+	// TemplateDuration observes the time spent executing the payload and header templates per rule.
+	TemplateDuration *prometheus.HistogramVec
+}
+
+// NewRemoteJSONMetrics creates the collectors of the remote_json authorizer. Their names start with
+// prefix, see serve.prometheus.metric_name_prefix.
+func NewRemoteJSONMetrics(prefix string) *RemoteJSONMetrics {
+	return &RemoteJSONMetrics{
+		AsyncDroppedTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: prefix + "remote_json_async_dropped_total",
+			Help: "Total number of asynchronous remote_json calls dropped because the queue was full",
+		}),
+		TemplateDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    prefix + "remote_json_template_duration_seconds",
+			Help:    "Time spent executing remote_json templates.",
+			Buckets: []float64{.00001, .00005, .0001, .0005, .001, .005, .01, .05, .1},
+		}, []string{"rule_id", "template"}),
+	}
+}
+
+// Collectors returns the collectors so that they can be registered.
+func (m *RemoteJSONMetrics) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.AsyncDroppedTotal, m.TemplateDuration}
+}
+
+type authorizerRemoteJSONDependencies interface {
+	x.RegistryLogger
+	Tracer() trace.Tracer
+	RemoteJSONMetrics() *RemoteJSONMetrics
+}
+
+// AuthorizerRemoteJSON implements the Authorizer interface.
+type AuthorizerRemoteJSON struct {
+	c      configuration.Provider
+	logger *logrusx.Logger
+
+	t       *template.Template
+	strictT *template.Template
+	tracer  trace.Tracer
+	metrics *RemoteJSONMetrics
+
+	transports   sync.Map
+	clients      sync.Map
+	payloadFiles sync.Map
+	balanced     sync.Map
+
+	// parseMu serializes parsing into the shared template sets, whose results are cached in templates by
+	// their source, so that colliding IDs never cause the wrong template to be executed.
+	parseMu   sync.Mutex
+	templates sync.Map
+
+	// closed is set by Shutdown. Afterwards, no dispatchers, caches or pooled connections are created anymore.
+	closed atomic.Bool
+
+	callsMu sync.Mutex
+	calls   map[string]*remoteJSONCall
+
+	dispatchersMu sync.Mutex
+	dispatchers   map[string]*remoteJSONDispatcher
+
+	cachesMu sync.Mutex
+	caches   map[string]*ristretto.Cache[string, *remoteJSONResponse]
+}
+
+// NewAuthorizerRemoteJSON creates a new AuthorizerRemoteJSON.
+func NewAuthorizerRemoteJSON(c configuration.Provider, d authorizerRemoteJSONDependencies) *AuthorizerRemoteJSON {
+	return &AuthorizerRemoteJSON{
+		c:       c,
+		logger:  d.Logger(),
+		t:       x.NewTemplate("remote_json"),
+		strictT: x.NewTemplate("remote_json_strict").Option("missingkey=error"),
+		tracer:  d.Tracer(),
+		metrics: d.RemoteJSONMetrics(),
+	}
+}
+
+// GetID implements the Authorizer interface.
+func (a *AuthorizerRemoteJSON) GetID() string {
+	return "remote_json"
+}
+
+// Shutdown implements the Shutdowner interface. It releases idle connections to the remote and waits
+// for queued asynchronous calls to finish. Requests still using the authorizer afterwards are served
+// without caching decisions, asynchronous calls are dropped, and connections are not kept open.
+func (a *AuthorizerRemoteJSON) Shutdown(ctx context.Context) error {
+	a.closed.Store(true)
+
+	a.clients.Range(func(_, client any) bool {
+		client.(*retryablehttp.Client).HTTPClient.CloseIdleConnections()
+		return true
+	})
+	a.transports.Range(func(_, transport any) bool {
+		transport.(*http.Transport).CloseIdleConnections()
+		return true
+	})
+
+	a.dispatchersMu.Lock()
+	dispatchers := a.dispatchers
+	a.dispatchers = nil
+	a.dispatchersMu.Unlock()
+
+	a.cachesMu.Lock()
+	for _, cache := range a.caches {
+		cache.Close()
+	}
+	a.caches = nil
+	a.cachesMu.Unlock()
+
+	var err error
+	for _, d := range dispatchers {
+		if stopErr := d.stop(ctx); stopErr != nil && err == nil {
+			err = stopErr
+		}
+	}
+	return err
+}
+
+// template returns the template with the given ID from templates, parsing text if it has not been parsed yet.
+// A cached template is only reused if it was parsed from the same text, so that colliding IDs never cause the
+// wrong template to be executed.
+func (a *AuthorizerRemoteJSON) template(templates *template.Template, c *AuthorizerRemoteJSONConfiguration, id, text string) (*template.Template, error) {
+	left, right := c.delims()
+	key := templates.Name() + "\x00" + id + "\x00" + left + "\x00" + right + "\x00" + text
+	if t, ok := a.templates.Load(key); ok {
+		return t.(*template.Template), nil
+	}
+
+	a.parseMu.Lock()
+	defer a.parseMu.Unlock()
+	if t, ok := a.templates.Load(key); ok {
+		return t.(*template.Template), nil
+	}
+
+	t, err := templates.New(id).Delims(left, right).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	a.templates.Store(key, t)
+	return t, nil
+}
+
+// payload returns the payload template. Payloads referencing a file with a file:// URL are loaded once
+// and then served from memory until the configuration is reloaded. As rules may be fetched from remote
+// repositories, files are only loaded from the directories listed in
+// authorizers.remote_json.payload_directories.
+func (a *AuthorizerRemoteJSON) payload(payload string) (string, error) {
+	path, ok := strings.CutPrefix(payload, "file://")
+	if !ok {
+		return payload, nil
+	}
+	if content, ok := a.payloadFiles.Load(payload); ok {
+		return content.(string), nil
+	}
+
+	if !a.payloadFileAllowed(path) {
+		return "", errors.Errorf(`the payload file "%s" is not located in a directory listed in "%s"`, path, configuration.AuthorizerRemoteJSONPayloadDirs)
+	}
+	content, err := x.FileOrContent(payload)
+	if err != nil {
+		return "", err
+	}
+	a.payloadFiles.Store(payload, content)
+	return content, nil
+}
+
+// payloadFileAllowed reports whether path is located in one of the directories payload files may be
+// loaded from. Symbolic links are resolved so that they cannot point outside of these directories.
+func (a *AuthorizerRemoteJSON) payloadFileAllowed(path string) bool {
+	path, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return false
+	}
+
+	for _, dir := range a.c.AuthorizerRemoteJSONPayloadDirectories() {
+		dir, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			continue
+		}
+		if dir, err = filepath.Abs(dir); err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(dir, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reload implements the Reloader interface. It drops the payload files loaded so far, so that they are
+// read again on next use.
+func (a *AuthorizerRemoteJSON) Reload() {
+	a.payloadFiles.Clear()
+}
+
+// transportKey identifies the transport of c and profile, see transport. It is empty if the default
+// transport suffices.
+func (c *AuthorizerRemoteJSONConfiguration) transportKey(profile *configuration.HTTPClientProfile) string {
+	if c.UnixSocket == "" && len(c.Resolve) == 0 && profile == nil {
+		return ""
+	}
+
+	key := "unix\x00" + c.UnixSocket
+	if len(c.Resolve) > 0 {
+		hosts := make([]string, 0, len(c.Resolve))
+		for host := range c.Resolve {
+			hosts = append(hosts, host)
+		}
+		sort.Strings(hosts)
+		key = "resolve"
+		for _, host := range hosts {
+			key += "\x00" + host + "=" + c.Resolve[host]
+		}
+	}
+	if profile != nil {
+		key += fmt.Sprintf("\x00profile=%+v", *profile)
+	}
+	return key
+}
+
+// transport returns the transport requests to the remote authorizer are sent with, or nil if the
+// default transport suffices. It dials the Unix domain socket UnixSocket instead of the host of the
+// request URL, or the IP address Resolve maps the host to (similar to curl's --resolve), and applies
+// the connection pooling and TLS settings of profile. Transports are reused so that connections are pooled.
+func (a *AuthorizerRemoteJSON) transport(c *AuthorizerRemoteJSONConfiguration, profile *configuration.HTTPClientProfile) (*http.Transport, error) {
+	key := c.transportKey(profile)
+	if key == "" {
+		return nil, nil
+	}
+	if transport, ok := a.transports.Load(key); ok {
+		return transport.(*http.Transport), nil
+	}
+
+	transport := &http.Transport{}
+	if profile != nil {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+		if profile.MaxIdleConns > 0 {
+			transport.MaxIdleConns = profile.MaxIdleConns
+		}
+		if profile.MaxIdleConnsPerHost > 0 {
+			transport.MaxIdleConnsPerHost = profile.MaxIdleConnsPerHost
+		}
+		if profile.IdleConnTimeout != "" {
+			idle, err := time.ParseDuration(profile.IdleConnTimeout)
+			if err != nil {
+				return nil, errors.Wrap(err, "invalid idle_conn_timeout")
+			}
+			transport.IdleConnTimeout = idle
+		}
+		if profile.TLS.InsecureSkipVerify {
+			transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // explicitly requested by the profile
+		}
+	}
+
+	var dialer net.Dialer
+	if path := c.UnixSocket; path != "" {
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", path)
+		}
+	} else if resolve := c.Resolve; len(resolve) > 0 {
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if ip, ok := resolve[addr]; ok {
+				_, port, err := net.SplitHostPort(addr)
+				if err != nil {
+					return nil, err
+				}
+				addr = net.JoinHostPort(ip, port)
+			} else if host, port, err := net.SplitHostPort(addr); err == nil {
+				if ip, ok := resolve[host]; ok {
+					addr = net.JoinHostPort(ip, port)
+				}
+			}
+			return dialer.DialContext(ctx, network, addr)
+		}
+	}
+
+	stored, _ := a.transports.LoadOrStore(key, transport)
+	return stored.(*http.Transport), nil
+}
+
+// Authorize implements the Authorizer interface.
+func (a *AuthorizerRemoteJSON) Authorize(r *http.Request, session *authn.AuthenticationSession, config json.RawMessage, rl pipeline.Rule) (err error) {
+	ctx, span := a.tracer.Start(r.Context(), "pipeline.authz.AuthorizerRemoteJSON.Authorize")
+	defer otelx.End(span, &err)
+	r = r.WithContext(ctx)
+
+	c, client, err := a.config(config)
+	if err != nil {
+		return err
+	}
+
+	// Requests with methods the authorizer does not apply to are allowed without asking the remote.
+	if c.BypassesMethod(r.Method) || (len(c.Methods) > 0 && !stringslice.HasI(c.Methods, r.Method)) {
+		return nil
+	}
+
+	if c.DeadlineHeader != "" {
+		budget, ok, err := remainingBudget(r.Header.Get(c.DeadlineHeader), time.Now())
+		if err != nil {
+			return errors.WithStack(helper.ErrBadRequest.WithReasonf(`The "%s" header is malformed: %s`, c.DeadlineHeader, err))
+		}
+		if ok {
+			if budget <= 0 {
+				return errors.WithStack(helper.ErrUpstreamServiceTimeout.WithReasonf(`The deadline from the "%s" header has already passed.`, c.DeadlineHeader))
+			}
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, budget)
+			defer cancel()
+			r = r.WithContext(ctx)
+		}
+	}
+
+	templates := a.t
+	if c.StrictTemplates {
+		templates = a.strictT
+	}
+
+	if c.Remote, err = a.renderRemote(templates, c, session, rl); err != nil {
+		return err
+	}
+
+	// Trailer values are only known once the body has been read. Requests declaring trailers are
+	// therefore read in full so that the payload can reference them as .MatchContext.Trailer. Bodies
+	// larger than body_spool_threshold are kept in a temporary file until they are forwarded.
+	if len(r.Trailer) > 0 {
+		if err := pipeRequestBody(r, io.Discard, c.BodySpoolThreshold); err != nil {
+			return errors.WithStack(err)
+		}
+		session.MatchContext.Trailer = r.Trailer.Clone()
+	}
+
+	start := time.Now()
+	body, err := a.renderPayload(templates, c, r.Method, session)
+	a.observeTemplateDuration(rl, "payload", start)
+	if err != nil {
+		return err
+	}
+
+	if len(bytes.TrimSpace(body.Bytes())) == 0 {
+		switch c.EmptyPayload {
+		case "empty_object":
+			body.Reset()
+			body.WriteString("{}")
+		case "allow":
+			return nil
+		default:
+			return errors.Errorf(`the payload template of rule "%s" rendered an empty payload`, rl.GetID())
+		}
+	}
+
+	if c.MaxPayloadBytes > 0 && body.Len() > c.MaxPayloadBytes {
+		return errors.Errorf(`the payload of rule "%s" has %d bytes which exceeds the maximum of %d bytes`, rl.GetID(), body.Len(), c.MaxPayloadBytes)
+	}
+
+	var j json.RawMessage
+	if err := json.Unmarshal(body.Bytes(), &j); err != nil {
+		return errors.Wrap(err, "payload is not a JSON text")
+	}
+
+	header := http.Header{}
+	header.Add("Content-Type", "application/json")
+	authz := r.Header.Get("Authorization")
+	if c.NormalizeAuthorization {
+		authz = normalizeAuthorization(authz)
+	}
+	if authz != "" {
+		header.Add("Authorization", authz)
+	}
+	for _, name := range c.ForwardRequestHeaders {
+		for _, value := range r.Header.Values(name) {
+			header.Add(name, value)
+		}
+	}
+
+	start = time.Now()
+	err = a.renderHeaders(templates, c, session, rl, header)
+	a.observeTemplateDuration(rl, "headers", start)
+	if err != nil {
+		return err
+	}
+
+	// max_payload_bytes limits the rendered payload, not the compressed one.
+	payload := body.Bytes()
+	if c.RequestCompression == "gzip" {
+		if payload, err = gzipPayload(payload); err != nil {
+			return err
+		}
+		header.Set("Content-Encoding", "gzip")
+	}
+
+	if c.Async {
+		a.dispatch(context.WithoutCancel(r.Context()), client, c, header, payload, rl)
+		return nil
+	}
+
+	res, attempts, err := a.doShared(r.Context(), client, c, header, payload, rl)
+	if err != nil {
+		recordRemoteJSONDecision(r.Context(), "error", nil, attempts)
+		return c.unavailable(err)
+	}
+	defer res.Body.Close() //nolint:errcheck // close failure cannot be handled here
+
+	if c.abstains(res.StatusCode) {
+		recordRemoteJSONDecision(r.Context(), "abstain", res, attempts)
+		a.logger.
+			WithField("event", "remote_json_abstained").
+			WithField("rule_id", rl.GetID()).
+			WithField("remote_host", res.Request.URL.Host).
+			Debugf("The remote authorizer abstained from deciding on the request with status code %d.", res.StatusCode)
+		return errors.WithStack(ErrAuthorizerNotResponsible)
+	} else if rd := c.RetryableDenial; rd != nil && slices.Contains(rd.StatusCodes, res.StatusCode) {
+		recordRemoteJSONDecision(r.Context(), "deny", res, attempts)
+		a.logger.
+			WithField("event", "remote_json_denied").
+			WithField("rule_id", rl.GetID()).
+			WithField("remote_host", res.Request.URL.Host).
+			Logf(c.denialLogLevel(), "The remote authorizer denied the request for now.")
+		return retryableDenial(rd, res)
+	} else if c.forbids(res.StatusCode) {
+		recordRemoteJSONDecision(r.Context(), "deny", res, attempts)
+		a.logger.
+			WithField("event", "remote_json_denied").
+			WithField("rule_id", rl.GetID()).
+			WithField("remote_host", res.Request.URL.Host).
+			Logf(c.denialLogLevel(), "The remote authorizer denied the request.")
+		return a.forbidden(templates, c, session, res, rl)
+	} else if !c.accepts(res.StatusCode) {
+		recordRemoteJSONDecision(r.Context(), "error", res, attempts)
+		return errors.Errorf("expected one of the status codes %v but got %d", c.AcceptStatusCodes, res.StatusCode)
+	}
+
+	recordRemoteJSONDecision(r.Context(), "allow", res, attempts)
+
+	for _, allowedHeader := range c.ResponseHeadersToForward(res.StatusCode) {
+		session.SetHeader(allowedHeader, res.Header.Get(allowedHeader))
+	}
+
+	if rs := c.ResponseStatus; rs != nil && rs.Key != "" {
+		status := map[string]interface{}{"status_code": res.StatusCode}
+		if rs.Header != "" {
+			status["header"] = res.Header.Get(rs.Header)
+		}
+		if session.Extra == nil {
+			session.Extra = map[string]interface{}{}
+		}
+		session.Extra[rs.Key] = status
+	}
+
+	return nil
+}
+
+// observeTemplateDuration records the time spent executing the templates of the given kind since start
+// in RemoteJSONMetrics.TemplateDuration, if enabled.
+func (a *AuthorizerRemoteJSON) observeTemplateDuration(rl pipeline.Rule, kind string, start time.Time) {
+	if a.c.PrometheusTemplateDurations() {
+		a.metrics.TemplateDuration.WithLabelValues(rl.GetID(), kind).Observe(time.Since(start).Seconds())
+	}
+}
+
+// renderPayload renders the payload template of requests with the given method for session.
+func (a *AuthorizerRemoteJSON) renderPayload(templates *template.Template, c *AuthorizerRemoteJSONConfiguration, method string, session *authn.AuthenticationSession) (*bytes.Buffer, error) {
+	payload := c.payloadFor(method)
+	t, err := a.template(templates, c, c.templateID(payload), payload)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var body bytes.Buffer
+	if err := t.Execute(&body, session); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &body, nil
+}
+
+// renderRemote renders the remote URL template for session and checks that its host is allowed, see
+// remoteAllowed. Remotes without template actions are returned as they are.
+func (a *AuthorizerRemoteJSON) renderRemote(templates *template.Template, c *AuthorizerRemoteJSONConfiguration, session *authn.AuthenticationSession, rl pipeline.Rule) (string, error) {
+	if !c.remoteTemplated() {
+		return c.Remote, nil
+	}
+
+	t, err := a.template(templates, c, c.templateID(c.Remote), c.Remote)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	var remote bytes.Buffer
+	if err := t.Execute(&remote, session); err != nil {
+		return "", errors.Wrapf(err, `error executing the remote template of rule "%s"`, rl.GetID())
+	}
+	if !absoluteURL(remote.String()) {
+		return "", errors.Errorf(`the remote of rule "%s" rendered to "%s" which is not an absolute URL`, rl.GetID(), remote.String())
+	}
+	if u, _ := url.Parse(remote.String()); !c.remoteAllowed(u) {
+		return "", errors.Errorf(`the remote of rule "%s" rendered to host "%s" which is not allowed`, rl.GetID(), u.Host)
+	}
+	return remote.String(), nil
+}
+
+// renderHeaders renders the header templates for session into header. Headers whose condition is not met or
+// whose template renders an empty value are left out.
+func (a *AuthorizerRemoteJSON) renderHeaders(templates *template.Template, c *AuthorizerRemoteJSONConfiguration, session *authn.AuthenticationSession, rl pipeline.Rule, header http.Header) error {
+	for hdr, templateString := range c.Headers {
+		if condition, ok := c.HeaderConditions[hdr]; ok {
+			tmpl, err := a.template(templates, c, fmt.Sprintf("%s:%s#condition", rl.GetID(), hdr), condition)
+			if err != nil {
+				return errors.Wrapf(err, `error parsing condition template "%s" of header "%s" in rule "%s"`, condition, hdr, rl.GetID())
+			}
+
+			var value bytes.Buffer
+			if err := tmpl.Execute(&value, session); err != nil {
+				return errors.Wrapf(err, `error executing condition template "%s" of header "%s" in rule "%s"`, condition, hdr, rl.GetID())
+			}
+			if !truthy(value.String()) {
+				continue
+			}
+		}
+
+		templateId := fmt.Sprintf("%s:%s", rl.GetID(), hdr)
+		tmpl, err := a.template(templates, c, templateId, templateString)
+		if err != nil {
+			return errors.Wrapf(err, `booo error parsing headers template "%s" in rule "%s"`, templateString, rl.GetID())
+		}
+
+		headerValue := bytes.Buffer{}
+		err = tmpl.Execute(&headerValue, session)
+		if err != nil {
+			return errors.Wrapf(err, `error executing headers template "%s" in rule "%s"`, templateString, rl.GetID())
+		}
+		// Don't send empty headers
+		if headerValue.String() == "" {
+			continue
+		}
+
+		header.Set(hdr, headerValue.String())
+	}
+	return nil
+}
+
+// Preview implements the Previewer interface. It renders the remote, the payload and the headers for
+// session like Authorize does, but does not call the remote. The values of rendered headers are masked,
+// as they may carry credentials.
+func (a *AuthorizerRemoteJSON) Preview(session *authn.AuthenticationSession, config json.RawMessage, rl pipeline.Rule) (*Preview, error) {
+	c, err := a.Config(config)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := a.t
+	if c.StrictTemplates {
+		templates = a.strictT
+	}
+
+	if c.Remote, err = a.renderRemote(templates, c, session, rl); err != nil {
+		return nil, err
+	}
+
+	body, err := a.renderPayload(templates, c, session.MatchContext.Method, session)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(body.Bytes())) == 0 {
+		switch c.EmptyPayload {
+		case "empty_object":
+			body = bytes.NewBufferString("{}")
+		case "allow":
+			return &Preview{Remote: c.Remote, Skipped: true}, nil
+		default:
+			return nil, errors.Errorf(`the payload template of rule "%s" rendered an empty payload`, rl.GetID())
+		}
+	}
+	if c.MaxPayloadBytes > 0 && body.Len() > c.MaxPayloadBytes {
+		return nil, errors.Errorf(`the payload of rule "%s" has %d bytes which exceeds the maximum of %d bytes`, rl.GetID(), body.Len(), c.MaxPayloadBytes)
+	}
+	if !json.Valid(body.Bytes()) {
+		return nil, errors.Errorf(`the payload of rule "%s" is not a JSON text`, rl.GetID())
+	}
+
+	header := http.Header{"Content-Type": {"application/json"}}
+	if err := a.renderHeaders(templates, c, session, rl, header); err != nil {
+		return nil, err
+	}
+	for name := range c.Headers {
+		if name = http.CanonicalHeaderKey(name); header.Get(name) != "" {
+			header.Set(name, "[masked]")
+		}
+	}
+
+	return &Preview{Remote: c.Remote, Body: body.Bytes(), Header: header}, nil
+}
+
+// Probe implements the Prober interface. It checks the health endpoint declared in the global
+// configuration of the authorizer and succeeds if no health check is configured. HTTP checks are sent
+// with the client of the global configuration, but are not retried. Checks are bounded by its timeout.
+func (a *AuthorizerRemoteJSON) Probe(ctx context.Context) error {
+	raw := a.c.Get(fmt.Sprintf("authorizers.%s.config", a.GetID()))
+	if raw == nil {
+		return nil
+	}
+
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var c AuthorizerRemoteJSONConfiguration
+	if err := json.Unmarshal(encoded, &c); err != nil {
+		return errors.WithStack(err)
+	}
+	hc := c.HealthCheck
+	if hc == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.callTimeout())
+	defer cancel()
+
+	switch hc.Type {
+	case "grpc":
+		return probeGRPC(ctx, hc)
+	default:
+		client, err := a.client(&c, nil)
+		if err != nil {
+			return NewErrAuthorizerMisconfigured(a, err)
+		}
+		return probeHTTP(ctx, client.single, hc)
+	}
+}
+
+func probeHTTP(ctx context.Context, client *http.Client, hc *AuthorizerRemoteJSONHealthCheckConfiguration) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hc.Endpoint, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer res.Body.Close() //nolint:errcheck // close failure cannot be handled here
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return errors.Errorf("health check of %s returned status code %d", hc.Endpoint, res.StatusCode)
+	}
+	return nil
+}
+
+func probeGRPC(ctx context.Context, hc *AuthorizerRemoteJSONHealthCheckConfiguration) error {
+	conn, err := grpc.NewClient(hc.Endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer conn.Close() //nolint:errcheck // close failure cannot be handled here
+
+	res, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: hc.Service})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if res.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+		return errors.Errorf("health check of %s returned status %s", hc.Endpoint, res.GetStatus())
+	}
+	return nil
+}
+
+// newRequest returns a request to endpoint carrying the payload. GET requests have no body and carry the
+// payload in the PayloadQueryParameter query parameter instead, if set.
+func (c *AuthorizerRemoteJSONConfiguration) newRequest(ctx context.Context, endpoint string, payload []byte) (*http.Request, error) {
+	if c.Method != http.MethodGet {
+		return http.NewRequestWithContext(ctx, c.Method, endpoint, bytes.NewReader(payload))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, c.Method, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.PayloadQueryParameter != "" {
+		query := req.URL.Query()
+		query.Set(c.PayloadQueryParameter, string(payload))
+		req.URL.RawQuery = query.Encode()
+	}
+	return req, nil
 }
 
-// AuthorizerRemoteJSON implements the Authorizer interface.
-type AuthorizerRemoteJSON struct {
-	c configuration.Provider
+// do sends the payload to the remote authorizers in the order returned by Endpoints until one of them
+// can be reached.
+func (a *AuthorizerRemoteJSON) do(ctx context.Context, client *remoteJSONClient, c *AuthorizerRemoteJSONConfiguration, header http.Header, payload []byte, rl pipeline.Rule) (*http.Response, error) {
+	var n uint64
+	if len(c.Remotes) > 0 {
+		// Each set of endpoints is balanced independently.
+		key := c.LoadBalance
+		for _, e := range c.Remotes {
+			key += "\x00" + e.URL
+		}
+		counter, _ := a.balanced.LoadOrStore(key, new(atomic.Uint64))
+		n = counter.(*atomic.Uint64).Add(1) - 1
+	}
 
-	client *http.Client
-	t      *template.Template
-	tracer trace.Tracer
-}
+	var err error
+	for _, endpoint := range c.Endpoints(n, rand.Intn) { //nolint:gosec // load balancing does not need a cryptographic source
+		req, reqErr := c.newRequest(ctx, endpoint, payload)
+		if reqErr != nil {
+			return nil, errors.WithStack(reqErr)
+		}
+		req.Header = header.Clone()
+		if c.Method == http.MethodGet {
+			req.Header.Del("Content-Type")
+		}
+		if c.HostHeader != "" {
+			req.Host = c.HostHeader
+		}
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("authz.remote_json.endpoint", endpoint))
 
-// NewAuthorizerRemoteJSON creates a new AuthorizerRemoteJSON.
-func NewAuthorizerRemoteJSON(c configuration.Provider, d interface{ Tracer() trace.Tracer }) *AuthorizerRemoteJSON {
-	return &AuthorizerRemoteJSON{
-		c:      c,
-		client: httpx.NewResilientClient().StandardClient(),
-		t:      x.NewTemplate("remote_json"),
-		tracer: d.Tracer(),
+		var res *http.Response
+		if res, err = client.Do(req); err == nil {
+			return res, nil
+		}
+
+		a.logger.WithError(err).
+			WithField("event", "remote_json_transport_error").
+			WithField("rule_id", rl.GetID()).
+			WithField("remote_host", req.URL.Host).
+			Warn("Unable to reach the remote authorizer.")
+		if ctx.Err() != nil {
+			break
+		}
 	}
+	return nil, errors.WithStack(err)
 }
 
-// GetID implements the Authorizer interface.
-func (a *AuthorizerRemoteJSON) GetID() string {
-	return "remote_json"
+// requestKey returns a key which identifies a request to the remote by its endpoints, routing, client, timeout,
+// headers and payload.
+func (c *AuthorizerRemoteJSONConfiguration) requestKey(client *remoteJSONClient, header http.Header, payload []byte) string {
+	key := sha256.New()
+	_, _ = fmt.Fprintf(key, "%s\x00%+v\x00%s\x00%s\x00%s\x00", c.Remote, c.Remotes, c.HostHeader, c.Method, c.PayloadQueryParameter)
+	_, _ = fmt.Fprintf(key, "%s\x00%v\x00%s\x00%s\x00%s\x00", c.UnixSocket, c.Resolve, c.ClientProfile, client.key, c.callTimeout())
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(key, "%s\x00%q\x00", name, header[name])
+	}
+	_, _ = key.Write(payload)
+	return string(key.Sum(nil))
 }
 
-// Authorize implements the Authorizer interface.
-func (a *AuthorizerRemoteJSON) Authorize(r *http.Request, session *authn.AuthenticationSession, config json.RawMessage, rl pipeline.Rule) (err error) {
-	ctx, span := a.tracer.Start(r.Context(), "pipeline.authz.AuthorizerRemoteJSON.Authorize")
-	defer otelx.End(span, &err)
-	r = r.WithContext(ctx)
+// decisionCache returns the cache of decisions for c and the time to live of its entries, or nil if c does
+// not cache decisions. Rules with the same time to live and maximum cost share a cache.
+func (a *AuthorizerRemoteJSON) decisionCache(c *AuthorizerRemoteJSONConfiguration) (*ristretto.Cache[string, *remoteJSONResponse], time.Duration) {
+	if c.Cache == nil {
+		return nil, 0
+	}
+	ttl, err := time.ParseDuration(c.Cache.TTL)
+	if err != nil || ttl <= 0 {
+		return nil, 0
+	}
+	cost := int64(c.Cache.MaxCost)
+	if cost <= 0 {
+		cost = 1000
+	}
 
-	c, err := a.Config(config)
+	a.cachesMu.Lock()
+	defer a.cachesMu.Unlock()
+	if a.closed.Load() {
+		return nil, 0
+	}
+
+	key := fmt.Sprintf("%s/%d", ttl, cost)
+	if cache, ok := a.caches[key]; ok {
+		return cache, ttl
+	}
+
+	cache, err := ristretto.NewCache(&ristretto.Config[string, *remoteJSONResponse]{
+		NumCounters:        cost * 10,
+		MaxCost:            cost,
+		BufferItems:        64,
+		IgnoreInternalCost: true,
+	})
 	if err != nil {
-		return err
+		a.logger.WithError(err).Warn("Unable to create the decision cache of the remote authorizer, decisions are not cached.")
+		return nil, 0
+	}
+	if a.caches == nil {
+		a.caches = map[string]*ristretto.Cache[string, *remoteJSONResponse]{}
 	}
+	a.caches[key] = cache
+	return cache, ttl
+}
 
-	templateID := c.PayloadTemplateID()
-	t := a.t.Lookup(templateID)
-	if t == nil {
-		var err error
-		t, err = a.t.New(templateID).Parse(c.Payload)
-		if err != nil {
-			return errors.WithStack(err)
+// doShared sends the payload like do, but concurrent calls with the same endpoints, routing, client, headers and
+// payload share a single call to the remote. Every caller receives its own copy of the response, or stops
+// waiting for it once its ctx is done. If c caches decisions, responses which allow or deny the request are
+// served from the cache until they expire. The number of attempts the shared call took is returned as well.
+func (a *AuthorizerRemoteJSON) doShared(ctx context.Context, client *remoteJSONClient, c *AuthorizerRemoteJSONConfiguration, header http.Header, payload []byte, rl pipeline.Rule) (*http.Response, int64, error) {
+	key := c.requestKey(client, header, payload)
+
+	cache, ttl := a.decisionCache(c)
+	if cache != nil {
+		if cached, ok := cache.Get(key); ok {
+			return cached.copy(), 0, nil
 		}
 	}
 
-	var body bytes.Buffer
-	if err := t.Execute(&body, session); err != nil {
-		return errors.WithStack(err)
+	a.callsMu.Lock()
+	call, ok := a.calls[key]
+	if !ok || !call.join(ctx) {
+		call = a.startCall(ctx, key, client, c, header, payload, rl)
 	}
+	a.callsMu.Unlock()
 
-	var j json.RawMessage
-	if err := json.Unmarshal(body.Bytes(), &j); err != nil {
-		return errors.Wrap(err, "payload is not a JSON text")
+	select {
+	case <-ctx.Done():
+		return nil, 0, errors.WithStack(ctx.Err())
+	case <-call.done:
 	}
 
-	req, err := http.NewRequestWithContext(r.Context(), "POST", c.Remote, &body)
-	if err != nil {
-		return errors.WithStack(err)
+	res := call.res
+	if call.err != nil {
+		return nil, res.attempts, call.err
 	}
-	req.Header.Add("Content-Type", "application/json")
-	authz := r.Header.Get("Authorization")
-	if authz != "" {
-		req.Header.Add("Authorization", authz)
+	if cache != nil && (c.accepts(res.res.StatusCode) || c.forbids(res.res.StatusCode)) {
+		cache.SetWithTTL(key, res, 1, ttl)
 	}
+	return res.copy(), res.attempts, nil
+}
 
-	for hdr, templateString := range c.Headers {
-		var tmpl *template.Template
-		var err error
+// remoteJSONCall is a call to the remote which is shared by concurrent requests, see doShared. The call does
+// not end with the context of the request which started it. Instead, it runs until the latest deadline of
+// the requests waiting for it, but no longer than the timeout of the configuration allows.
+type remoteJSONCall struct {
+	done chan struct{}
+	res  *remoteJSONResponse
+	err  error
 
-		templateId := fmt.Sprintf("%s:%s", rl.GetID(), hdr)
-		tmpl = a.t.Lookup(templateId)
-		if tmpl == nil {
-			tmpl, err = a.t.New(templateId).Parse(templateString)
-			if err != nil {
-				return errors.Wrapf(err, `booo error parsing headers template "%s" in rule "%s"`, templateString, rl.GetID())
-			}
+	ctx      context.Context
+	timer    *time.Timer
+	deadline time.Time
+	limit    time.Time
+}
+
+// join extends the deadline of the call to the deadline of ctx, but not beyond the limit of the call. It
+// reports false if the call has already run out of time, in which case ctx must not wait for it. The caller
+// must hold AuthorizerRemoteJSON.callsMu.
+func (call *remoteJSONCall) join(ctx context.Context) bool {
+	if call.ctx.Err() != nil {
+		return false
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok || deadline.After(call.limit) {
+		deadline = call.limit
+	}
+	if !deadline.After(call.deadline) {
+		return true
+	}
+	if !call.timer.Stop() {
+		return false
+	}
+	call.deadline = deadline
+	call.timer.Reset(time.Until(deadline))
+	return true
+}
+
+// startCall starts a call to the remote which requests with the given key can join. The caller must hold
+// AuthorizerRemoteJSON.callsMu.
+func (a *AuthorizerRemoteJSON) startCall(ctx context.Context, key string, client *remoteJSONClient, c *AuthorizerRemoteJSONConfiguration, header http.Header, payload []byte, rl pipeline.Rule) *remoteJSONCall {
+	attempts := new(atomic.Int64)
+	callCtx, cancel := context.WithCancelCause(context.WithValue(context.WithoutCancel(ctx), remoteJSONAttemptsKey{}, attempts))
+
+	call := &remoteJSONCall{done: make(chan struct{}), ctx: callCtx, limit: time.Now().Add(c.callTimeout())}
+	call.deadline = call.limit
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(call.limit) {
+		call.deadline = deadline
+	}
+	call.timer = time.AfterFunc(time.Until(call.deadline), func() { cancel(context.DeadlineExceeded) })
+
+	if a.calls == nil {
+		a.calls = map[string]*remoteJSONCall{}
+	}
+	a.calls[key] = call
+
+	go func() {
+		defer cancel(nil)
+
+		res, err := a.do(callCtx, client, c, header, payload, rl)
+		result := &remoteJSONResponse{res: res}
+		if err == nil {
+			result.body, err = io.ReadAll(io.LimitReader(res.Body, maxDenialBodySize))
+			_ = res.Body.Close() // close failure cannot be handled here
+			res.Body = nil
+			err = errors.WithStack(err)
+		}
+		result.attempts = attempts.Load()
+		if a.closed.Load() {
+			client.single.CloseIdleConnections()
+		}
+		if err != nil && errors.Is(context.Cause(callCtx), context.DeadlineExceeded) {
+			// The client reports the cancellation of the call, which happens once no waiter has time left.
+			err = errors.WithStack(context.DeadlineExceeded)
 		}
 
-		headerValue := bytes.Buffer{}
-		err = tmpl.Execute(&headerValue, session)
+		a.callsMu.Lock()
+		call.timer.Stop()
+		if a.calls[key] == call {
+			delete(a.calls, key)
+		}
+		a.callsMu.Unlock()
+
+		call.res, call.err = result, err
+		close(call.done)
+	}()
+	return call
+}
+
+// remoteJSONResponse is a response of the remote whose body has been read so that it can be shared, and
+// the number of attempts it took.
+type remoteJSONResponse struct {
+	res      *http.Response
+	body     []byte
+	attempts int64
+}
+
+func (r *remoteJSONResponse) copy() *http.Response {
+	res := *r.res
+	res.Header = r.res.Header.Clone()
+	res.Body = io.NopCloser(bytes.NewReader(r.body))
+	return &res
+}
+
+// remoteJSONAttemptsKey is the context key of the counter the resilient client increments on every
+// attempt to reach the remote, including retries.
+type remoteJSONAttemptsKey struct{}
+
+func countRemoteJSONAttempt(_ retryablehttp.Logger, req *http.Request, _ int) {
+	if attempts, ok := req.Context().Value(remoteJSONAttemptsKey{}).(*atomic.Int64); ok {
+		attempts.Add(1)
+	}
+}
+
+// recordRemoteJSONDecision adds an "authz.decision" event to the span of ctx. The event only carries the
+// outcome and metadata of the response, never the payload or the session.
+func recordRemoteJSONDecision(ctx context.Context, outcome string, res *http.Response, attempts int64) {
+	retries := attempts - 1
+	if retries < 0 {
+		retries = 0
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("authz.outcome", outcome),
+		attribute.Int64("authz.retries", retries),
+	}
+	if res != nil {
+		attrs = append(attrs,
+			attribute.Int("http.response.status_code", res.StatusCode),
+			attribute.String("server.address", res.Request.URL.Host),
+		)
+	}
+	trace.SpanFromContext(ctx).AddEvent("authz.decision", trace.WithAttributes(attrs...))
+}
+
+// remoteJSONDispatcher sends asynchronous calls to the remote using a fixed number of workers.
+type remoteJSONDispatcher struct {
+	queue chan func()
+	wg    sync.WaitGroup
+}
+
+func newRemoteJSONDispatcher(workers, size int) *remoteJSONDispatcher {
+	d := &remoteJSONDispatcher{queue: make(chan func(), size)}
+	d.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer d.wg.Done()
+			for call := range d.queue {
+				call()
+			}
+		}()
+	}
+	return d
+}
+
+// submit queues the call and reports whether there was room for it.
+func (d *remoteJSONDispatcher) submit(call func()) bool {
+	select {
+	case d.queue <- call:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop waits until all queued calls are done or ctx is done.
+func (d *remoteJSONDispatcher) stop(ctx context.Context) error {
+	close(d.queue)
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	}
+}
+
+// dispatch queues the call to the remote without waiting for its result. Rules with the same pool size
+// share a dispatcher. Each call is bounded by the timeout of c once a worker picks it up. If the queue is
+// full, the call is dropped and counted in RemoteJSONMetrics.AsyncDroppedTotal.
+func (a *AuthorizerRemoteJSON) dispatch(ctx context.Context, client *remoteJSONClient, c *AuthorizerRemoteJSONConfiguration, header http.Header, payload []byte, rl pipeline.Rule) {
+	workers, size := c.AsyncWorkers, c.AsyncQueueSize
+	if workers < 1 {
+		workers = 1
+	}
+	if size < 0 {
+		size = 0
+	}
+
+	call := func() {
+		ctx, cancel := context.WithTimeout(ctx, c.callTimeout())
+		defer cancel()
+
+		res, err := a.do(ctx, client, c, header, payload, rl)
 		if err != nil {
-			return errors.Wrapf(err, `error executing headers template "%s" in rule "%s"`, templateString, rl.GetID())
+			return
 		}
-		// Don't send empty headers
-		if headerValue.String() == "" {
-			continue
+		defer res.Body.Close()               //nolint:errcheck // close failure cannot be handled here
+		_, _ = io.Copy(io.Discard, res.Body) // drain the body so that the connection can be reused
+
+		if !c.accepts(res.StatusCode) {
+			a.logger.
+				WithField("event", "remote_json_async_rejected").
+				WithField("rule_id", rl.GetID()).
+				WithField("status_code", res.StatusCode).
+				Debug("The remote authorizer did not accept an asynchronous call.")
+		}
+	}
+
+	a.dispatchersMu.Lock()
+	defer a.dispatchersMu.Unlock()
+	if a.closed.Load() {
+		a.metrics.AsyncDroppedTotal.Inc()
+		a.logger.
+			WithField("event", "remote_json_async_dropped").
+			WithField("rule_id", rl.GetID()).
+			Warn("Dropped an asynchronous call to the remote authorizer because the authorizer has been shut down.")
+		return
+	}
+
+	key := fmt.Sprintf("%d/%d", workers, size)
+	d, ok := a.dispatchers[key]
+	if !ok {
+		if a.dispatchers == nil {
+			a.dispatchers = map[string]*remoteJSONDispatcher{}
 		}
+		d = newRemoteJSONDispatcher(workers, size)
+		a.dispatchers[key] = d
+	}
+
+	if !d.submit(call) {
+		a.metrics.AsyncDroppedTotal.Inc()
+		a.logger.
+			WithField("event", "remote_json_async_dropped").
+			WithField("rule_id", rl.GetID()).
+			Warn("Dropped an asynchronous call to the remote authorizer because the queue is full.")
+	}
+}
 
-		req.Header.Set(hdr, headerValue.String())
+// remoteJSONDenial is the data the www_authenticate template is executed against.
+type remoteJSONDenial struct {
+	Session *authn.AuthenticationSession
+	Header  http.Header
+	Body    interface{}
+}
+
+// maxDenialBodySize limits how much of a denial response body is decoded for the www_authenticate template.
+const maxDenialBodySize = 1 << 20
+
+// forbidden returns the error for a request denied by the remote. If configured, the error carries a
+// WWW-Authenticate header rendered from the authentication session and the remote's response.
+func (a *AuthorizerRemoteJSON) forbidden(templates *template.Template, c *AuthorizerRemoteJSONConfiguration, session *authn.AuthenticationSession, res *http.Response, rl pipeline.Rule) error {
+	if c.WWWAuthenticate == "" {
+		return errors.WithStack(helper.ErrForbidden)
 	}
 
-	res, err := a.client.Do(req.WithContext(r.Context()))
+	tmpl, err := a.template(templates, c, fmt.Sprintf("%s#www_authenticate", rl.GetID()), c.WWWAuthenticate)
 	if err != nil {
-		return errors.WithStack(err)
+		return errors.Wrapf(err, `error parsing www_authenticate template "%s" in rule "%s"`, c.WWWAuthenticate, rl.GetID())
 	}
-	defer res.Body.Close() //nolint:errcheck // close failure cannot be handled here
 
-	if res.StatusCode == http.StatusForbidden {
+	data := remoteJSONDenial{Session: session, Header: res.Header}
+	// The body is optional, a response which is not a JSON text is passed as nil. Numbers are kept as
+	// json.Number so that integers beyond float64 precision, such as 64-bit IDs, are rendered exactly.
+	decoder := json.NewDecoder(io.LimitReader(res.Body, maxDenialBodySize))
+	decoder.UseNumber()
+	_ = decoder.Decode(&data.Body)
+
+	var value bytes.Buffer
+	if err := tmpl.Execute(&value, &data); err != nil {
+		return errors.Wrapf(err, `error executing www_authenticate template "%s" in rule "%s"`, c.WWWAuthenticate, rl.GetID())
+	}
+	if value.Len() == 0 {
 		return errors.WithStack(helper.ErrForbidden)
-	} else if res.StatusCode != http.StatusOK {
-		return errors.Errorf("expected status code %d but got %d", http.StatusOK, res.StatusCode)
 	}
 
-	for _, allowedHeader := range c.ForwardResponseHeadersToUpstream {
-		session.SetHeader(allowedHeader, res.Header.Get(allowedHeader))
+	return helper.WithHeader(errors.WithStack(helper.ErrForbidden), http.Header{"Www-Authenticate": {value.String()}})
+}
+
+// retryableDenial returns an error which asks the client to retry the request after the delay the remote
+// responded with.
+func retryableDenial(c *AuthorizerRemoteJSONRetryableDenialConfiguration, res *http.Response) error {
+	retryAfter := res.Header.Get("Retry-After")
+	if retryAfter == "" && c.RetryAfterPath != "" {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, maxDenialBodySize))
+		retryAfter = gjson.GetBytes(body, c.RetryAfterPath).String()
+	}
+	if retryAfter == "" {
+		return errors.WithStack(helper.ErrTooManyRequests)
 	}
+	return helper.WithHeader(errors.WithStack(helper.ErrTooManyRequests), http.Header{"Retry-After": {retryAfter}})
+}
 
-	return nil
+// remainingBudget returns the time left until the deadline carried in a deadline header value. The value is
+// either a duration relative to now (e.g. "250ms") or an absolute RFC 3339 timestamp. The second return value
+// is false if the header value is empty.
+func remainingBudget(value string, now time.Time) (time.Duration, bool, error) {
+	if value == "" {
+		return 0, false, nil
+	}
+	if budget, err := time.ParseDuration(value); err == nil {
+		return budget, true, nil
+	}
+	deadline, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return 0, false, errors.Errorf("expected a duration or an RFC 3339 timestamp but got %q", value)
+	}
+	return deadline.Sub(now), true, nil
 }
 
 // Validate implements the Authorizer interface.
@@ -168,15 +1564,249 @@ func (a *AuthorizerRemoteJSON) Validate(config json.RawMessage) error {
 // Config merges config and the authorizer's configuration and validates the
 // resulting configuration. It reports an error if the configuration is invalid.
 func (a *AuthorizerRemoteJSON) Config(config json.RawMessage) (*AuthorizerRemoteJSONConfiguration, error) {
+	c, _, err := a.config(config)
+	return c, err
+}
+
+// config works like Config, but also returns the client requests of the configuration are sent with.
+func (a *AuthorizerRemoteJSON) config(config json.RawMessage) (*AuthorizerRemoteJSONConfiguration, *remoteJSONClient, error) {
 	var c AuthorizerRemoteJSONConfiguration
 	if err := a.c.AuthorizerConfig(a.GetID(), config, &c); err != nil {
-		return nil, NewErrAuthorizerMisconfigured(a, err)
+		return nil, nil, NewErrAuthorizerMisconfigured(a, err)
 	}
 
 	if c.ForwardResponseHeadersToUpstream == nil {
 		c.ForwardResponseHeadersToUpstream = []string{}
 	}
 
+	payload, err := a.payload(c.Payload)
+	if err != nil {
+		return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Wrap(err, "unable to load the payload template"))
+	}
+	c.Payload = payload
+
+	if len(c.MethodPayloads) > 0 {
+		payloads := make(map[string]string, len(c.MethodPayloads))
+		for method, payload := range c.MethodPayloads {
+			if payloads[strings.ToUpper(method)], err = a.payload(payload); err != nil {
+				return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Wrapf(err, `unable to load the payload template for method "%s"`, method))
+			}
+		}
+		c.MethodPayloads = payloads
+	}
+
+	if c.AbstainStatusCodes == nil {
+		c.AbstainStatusCodes = []int{http.StatusNotFound}
+	}
+
+	if c.AcceptStatusCodes == nil {
+		c.AcceptStatusCodes = []int{http.StatusOK}
+	}
+
+	if c.ForbiddenStatusCodes == nil {
+		c.ForbiddenStatusCodes = []int{http.StatusForbidden}
+	}
+
+	for _, code := range c.AcceptStatusCodes {
+		if c.forbids(code) {
+			return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Errorf(`status code %d is listed in both accept_status_codes and forbidden_status_codes`, code))
+		}
+	}
+
+	// Abstaining is checked first, so codes listed elsewhere as well would silently abstain. As
+	// abstain_status_codes defaults to [404], this mostly catches 404 being accepted or forbidden.
+	for _, code := range c.AbstainStatusCodes {
+		if c.accepts(code) {
+			return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Errorf(`status code %d is listed in both abstain_status_codes and accept_status_codes, abstain_status_codes defaults to [404]`, code))
+		}
+		if c.forbids(code) {
+			return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Errorf(`status code %d is listed in both abstain_status_codes and forbidden_status_codes, abstain_status_codes defaults to [404]`, code))
+		}
+	}
+
+	for _, code := range c.RetryOnStatus {
+		if slices.Contains(c.NoRetryOnStatus, code) {
+			return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Errorf(`status code %d is listed in both retry_on_status and no_retry_on_status`, code))
+		}
+	}
+
+	if c.TimeoutStatusCode == 0 {
+		c.TimeoutStatusCode = http.StatusServiceUnavailable
+	}
+
+	if len(c.Headers) > 0 {
+		headers := make(map[string]string, len(c.Headers))
+		for name, value := range c.Headers {
+			if !validHeaderName(name) {
+				return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Errorf(`header name "%s" is not a valid HTTP header name`, name))
+			}
+			canonical := http.CanonicalHeaderKey(name)
+			if _, ok := headers[canonical]; ok {
+				return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Errorf(`header "%s" is configured more than once`, canonical))
+			}
+			headers[canonical] = value
+		}
+		c.Headers = headers
+	}
+
+	if c.Remote != "" {
+		remote := c.Remote
+		if c.remoteTemplated() {
+			if _, err := a.template(a.t, &c, c.templateID(c.Remote), c.Remote); err != nil {
+				return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Wrap(err, "unable to parse the remote template"))
+			}
+			left, right := c.actionDelims()
+			remote = withoutActions(remote, left, right)
+		}
+		if !absoluteURL(remote) {
+			return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Errorf(`remote "%s" is not an absolute URL`, c.Remote))
+		}
+		if _, ok := c.remoteOrigin(); c.remoteTemplated() && !ok && len(c.RemoteHosts) == 0 {
+			return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Errorf(`the scheme or host of remote "%s" is templated, which requires remote_hosts`, c.Remote))
+		}
+	}
+
+	if c.Timeout != "" {
+		if timeout, err := time.ParseDuration(c.Timeout); err != nil || timeout <= 0 {
+			return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Errorf(`timeout "%s" is not a positive duration`, c.Timeout))
+		}
+	}
+
+	if c.Cache != nil {
+		if ttl, err := time.ParseDuration(c.Cache.TTL); err != nil || ttl <= 0 {
+			return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Errorf(`cache ttl "%s" is not a positive duration`, c.Cache.TTL))
+		}
+	}
+
+	for k, name := range c.ForwardRequestHeaders {
+		if !validHeaderName(name) {
+			return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Errorf(`header name "%s" in forward_request_headers is not a valid HTTP header name`, name))
+		}
+		c.ForwardRequestHeaders[k] = http.CanonicalHeaderKey(name)
+		if c.ForwardRequestHeaders[k] == "Authorization" || c.ForwardRequestHeaders[k] == "Content-Type" {
+			return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Errorf(`header "%s" in forward_request_headers is set by the authorizer and can not be forwarded`, name))
+		}
+	}
+
+	if len(c.HeaderConditions) > 0 {
+		conditions := make(map[string]string, len(c.HeaderConditions))
+		for name, condition := range c.HeaderConditions {
+			canonical := http.CanonicalHeaderKey(name)
+			if _, ok := c.Headers[canonical]; !ok {
+				return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Errorf(`header_conditions references header "%s" which is not configured in headers`, name))
+			}
+			conditions[canonical] = condition
+		}
+		c.HeaderConditions = conditions
+	}
+
+	if d := c.TemplateDelimiters; d != nil && (d.Left == "" || d.Right == "") {
+		return nil, nil, NewErrAuthorizerMisconfigured(a, errors.New("template_delimiters must set both the left and the right delimiter"))
+	}
+
+	if c.UnixSocket != "" && len(c.Resolve) > 0 {
+		return nil, nil, NewErrAuthorizerMisconfigured(a, errors.New("unix_socket and resolve can not be used together"))
+	}
+	for host, ip := range c.Resolve {
+		if net.ParseIP(ip) == nil {
+			return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Errorf(`resolve maps "%s" to "%s" which is not an IP address`, host, ip))
+		}
+	}
+	if c.Method == "" {
+		c.Method = http.MethodPost
+	}
+	if c.Method == http.MethodGet && c.RequestCompression == "gzip" {
+		return nil, nil, NewErrAuthorizerMisconfigured(a, errors.New("request_compression can not be used with method GET because GET requests have no body"))
+	}
+
+	if c.HostHeader != "" && !validHost(c.HostHeader) {
+		return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Errorf(`host_header "%s" is not a valid host`, c.HostHeader))
+	}
+
+	if c.StrictTemplates {
+		left, right := c.delims()
+		if err := validateSessionTemplate(c.Payload, left, right); err != nil {
+			return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Wrap(err, "invalid payload template"))
+		}
+		for method, payload := range c.MethodPayloads {
+			if err := validateSessionTemplate(payload, left, right); err != nil {
+				return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Wrapf(err, `invalid payload template for method "%s"`, method))
+			}
+		}
+		for hdr, templateString := range c.Headers {
+			if err := validateSessionTemplate(templateString, left, right); err != nil {
+				return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Wrapf(err, `invalid template for header "%s"`, hdr))
+			}
+		}
+		for hdr, condition := range c.HeaderConditions {
+			if err := validateSessionTemplate(condition, left, right); err != nil {
+				return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Wrapf(err, `invalid condition template for header "%s"`, hdr))
+			}
+		}
+		if err := validateTemplate(c.WWWAuthenticate, left, right, reflect.TypeOf(remoteJSONDenial{})); err != nil {
+			return nil, nil, NewErrAuthorizerMisconfigured(a, errors.Wrap(err, "invalid www_authenticate template"))
+		}
+	}
+
+	client, err := a.client(&c, config)
+	if err != nil {
+		return nil, nil, NewErrAuthorizerMisconfigured(a, err)
+	}
+
+	return &c, client, nil
+}
+
+// client resolves the retry settings of c, which the rule configuration config may override, and returns
+// the client requests of c are sent with.
+func (a *AuthorizerRemoteJSON) client(c *AuthorizerRemoteJSONConfiguration, config json.RawMessage) (*remoteJSONClient, error) {
+	if c.Retry == nil {
+		c.Retry = new(AuthorizerRemoteJSONRetryConfiguration)
+	}
+
+	var profile *configuration.HTTPClientProfile
+	if c.ClientProfile != "" {
+		var err error
+		if profile, err = a.c.HTTPClientProfile(c.ClientProfile); err != nil {
+			return nil, err
+		}
+
+		// Retry settings of the rule take precedence over the ones of the profile.
+		if profile.Retry.MaxDelay != "" && !gjson.GetBytes(config, "retry.max_delay").Exists() {
+			c.Retry.Timeout = profile.Retry.MaxDelay
+		}
+		if profile.Retry.GiveUpAfter != "" && !gjson.GetBytes(config, "retry.give_up_after").Exists() {
+			c.Retry.MaxWait = profile.Retry.GiveUpAfter
+		}
+	}
+
+	// Configurations which bypass the defaults of the schema fall back to the same values.
+	if c.Retry.Timeout == "" {
+		c.Retry.Timeout = "100ms"
+	}
+	if c.Retry.MaxWait == "" {
+		c.Retry.MaxWait = "1s"
+	}
+
+	return a.httpClient(c, profile)
+}
+
+// remoteJSONClient is a client requests to the remote are sent with and the key it is reused under.
+type remoteJSONClient struct {
+	*http.Client
+	// single sends requests with the same transport and timeout as Client, but does not retry them.
+	single *http.Client
+	key    string
+}
+
+// httpClient returns the client requests of c are sent with, using the settings of the HTTP client profile
+// of c, if any. A client is built once for every combination of transport and retry settings and then
+// reused, so that concurrent requests of rules with different settings never share a client.
+func (a *AuthorizerRemoteJSON) httpClient(c *AuthorizerRemoteJSONConfiguration, profile *configuration.HTTPClientProfile) (*remoteJSONClient, error) {
+	key := fmt.Sprintf("%s\x00%s\x00%v\x00%v\x00%s", c.Retry.Timeout, c.Retry.MaxWait, c.RetryOnStatus, c.NoRetryOnStatus, c.transportKey(profile))
+	if client, ok := a.clients.Load(key); ok {
+		return newRemoteJSONClient(client.(*retryablehttp.Client), key), nil
+	}
+
 	duration, err := time.ParseDuration(c.Retry.Timeout)
 	if err != nil {
 		return nil, err
@@ -187,10 +1817,39 @@ func (a *AuthorizerRemoteJSON) Config(config json.RawMessage) (*AuthorizerRemote
 		return nil, err
 	}
 	timeout := time.Millisecond * duration
-	a.client = httpx.NewResilientClient(
+	opts := []httpx.ResilientOptions{
 		httpx.ResilientClientWithMaxRetryWait(maxWait),
 		httpx.ResilientClientWithConnectionTimeout(timeout),
-	).StandardClient()
+	}
+	if profile != nil {
+		if profile.Timeout != "" {
+			if timeout, err = time.ParseDuration(profile.Timeout); err != nil {
+				return nil, errors.Wrapf(err, `invalid timeout in HTTP client profile "%s"`, c.ClientProfile)
+			}
+			opts = append(opts, httpx.ResilientClientWithConnectionTimeout(timeout))
+		}
+		if profile.MaxRetries > 0 {
+			opts = append(opts, httpx.ResilientClientWithMaxRetry(profile.MaxRetries))
+		}
+	}
+	client := httpx.NewResilientClient(opts...)
+	client.RequestLogHook = countRemoteJSONAttempt
+	if len(c.RetryOnStatus) > 0 || len(c.NoRetryOnStatus) > 0 {
+		retry := &AuthorizerRemoteJSONConfiguration{RetryOnStatus: c.RetryOnStatus, NoRetryOnStatus: c.NoRetryOnStatus}
+		client.CheckRetry = retry.checkRetry
+	}
+	transport, err := a.transport(c, profile)
+	if err != nil {
+		return nil, errors.Wrapf(err, `invalid HTTP client profile "%s"`, c.ClientProfile)
+	}
+	if transport != nil {
+		client.HTTPClient.Transport = transport
+	}
+
+	stored, _ := a.clients.LoadOrStore(key, client)
+	return newRemoteJSONClient(stored.(*retryablehttp.Client), key), nil
+}
 
-	return &c, nil
+func newRemoteJSONClient(client *retryablehttp.Client, key string) *remoteJSONClient {
+	return &remoteJSONClient{Client: client.StandardClient(), single: client.HTTPClient, key: key}
 }
@@ -4,28 +4,82 @@
 package authz_test
 
 import (
+	"bufio"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tidwall/sjson"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
 
+	"github.com/ory/herodot"
 	"github.com/ory/x/configx"
 	"github.com/ory/x/logrusx"
 
 	"github.com/ory/oathkeeper/driver/configuration"
+	"github.com/ory/oathkeeper/helper"
 	"github.com/ory/oathkeeper/pipeline/authn"
 	. "github.com/ory/oathkeeper/pipeline/authz"
 	"github.com/ory/oathkeeper/rule"
 	"github.com/ory/x/otelx"
 )
 
+type remoteJSONDependencies struct {
+	l *logrusx.Logger
+	t trace.Tracer
+	m *RemoteJSONMetrics
+}
+
+func newRemoteJSONDependencies(l *logrusx.Logger, p configuration.Provider) *remoteJSONDependencies {
+	return &remoteJSONDependencies{
+		l: l,
+		t: otelx.NewNoop(l, p.TracingConfig()).Tracer(),
+		m: NewRemoteJSONMetrics(p.PrometheusMetricsNamePrefix()),
+	}
+}
+
+func (d *remoteJSONDependencies) Logger() *logrusx.Logger               { return d.l }
+func (d *remoteJSONDependencies) Tracer() trace.Tracer                  { return d.t }
+func (d *remoteJSONDependencies) RemoteJSONMetrics() *RemoteJSONMetrics { return d.m }
+
+// newTestAuthorizerRemoteJSON returns a remote_json authorizer using the default configuration, which is
+// shut down when the test ends. The configuration is returned so that tests can change it.
+func newTestAuthorizerRemoteJSON(t *testing.T) (*AuthorizerRemoteJSON, *configuration.KoanfProvider) {
+	l := logrusx.New("", "")
+	p, err := configuration.NewKoanfProvider(context.Background(), nil, l)
+	require.NoError(t, err)
+	a := NewAuthorizerRemoteJSON(p, newRemoteJSONDependencies(l, p))
+	t.Cleanup(func() { _ = a.Shutdown(context.Background()) })
+	return a, p
+}
+
 func TestAuthorizerRemoteJSONAuthorize(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -33,6 +87,8 @@ func TestAuthorizerRemoteJSONAuthorize(t *testing.T) {
 		setup              func(t *testing.T) *httptest.Server
 		session            *authn.AuthenticationSession
 		sessionHeaderMatch *http.Header
+		requestHeader      http.Header
+		requestMethod      string
 		config             json.RawMessage
 		wantErr            bool
 	}{
@@ -147,6 +203,32 @@ func TestAuthorizerRemoteJSONAuthorize(t *testing.T) {
 			sessionHeaderMatch: &http.Header{"X-Foo": []string{""}},
 			config:             json.RawMessage(`{"payload":"{}","forward_response_headers_to_upstream":["X-Foo"]}`),
 		},
+		{
+			name: "ok with response headers scoped to status",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.Header().Set("X-Foo", "bar")
+					w.Header().Set("X-Scope", "read")
+					w.WriteHeader(http.StatusOK)
+				}))
+			},
+			session:            new(authn.AuthenticationSession),
+			sessionHeaderMatch: &http.Header{"X-Scope": []string{"read"}},
+			config:             json.RawMessage(`{"payload":"{}","forward_response_headers_to_upstream":["X-Foo"],"forward_response_headers_to_upstream_by_status":{"200":["X-Scope"],"204":["X-Foo"]}}`),
+		},
+		{
+			name: "ok with response headers falling back for unlisted status",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.Header().Set("X-Foo", "bar")
+					w.Header().Set("X-Scope", "read")
+					w.WriteHeader(http.StatusOK)
+				}))
+			},
+			session:            new(authn.AuthenticationSession),
+			sessionHeaderMatch: &http.Header{"X-Foo": []string{"bar"}},
+			config:             json.RawMessage(`{"payload":"{}","forward_response_headers_to_upstream":["X-Foo"],"forward_response_headers_to_upstream_by_status":{"204":["X-Scope"]}}`),
+		},
 		{
 			name: "authentication session",
 			setup: func(t *testing.T) *httptest.Server {
@@ -183,6 +265,195 @@ func TestAuthorizerRemoteJSONAuthorize(t *testing.T) {
 			},
 			config: json.RawMessage(`{"payload":"{\"match\":\"baz\"}","headers":{"Subject":"{{ .Subject }}","Empty-Header":""}}`),
 		},
+		{
+			name:    "strict templates with missing extra key",
+			session: &authn.AuthenticationSession{Subject: "alice"},
+			config:  json.RawMessage(`{"remote":"http://host/path","payload":"{}","strict_templates":true,"headers":{"X-Tenant":"{{ .Extra.tenant }}"}}`),
+			wantErr: true,
+		},
+		{
+			name: "lenient templates with missing extra key",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					assert.NotContains(t, r.Header, "X-Tenant")
+					w.WriteHeader(http.StatusOK)
+				}))
+			},
+			session: &authn.AuthenticationSession{Subject: "alice"},
+			config:  json.RawMessage(`{"payload":"{}","headers":{"X-Tenant":"{{ print .Extra.tenant }}"}}`),
+		},
+		{
+			name: "deadline header absent",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				}))
+			},
+			session: &authn.AuthenticationSession{},
+			config:  json.RawMessage(`{"payload":"{}","deadline_header":"X-Request-Deadline"}`),
+		},
+		{
+			name: "deadline header with remaining budget",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				}))
+			},
+			session:       &authn.AuthenticationSession{},
+			requestHeader: http.Header{"X-Request-Deadline": {time.Now().Add(time.Minute).Format(time.RFC3339)}},
+			config:        json.RawMessage(`{"payload":"{}","deadline_header":"X-Request-Deadline"}`),
+		},
+		{
+			name: "deadline header with remaining budget as duration",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				}))
+			},
+			session:       &authn.AuthenticationSession{},
+			requestHeader: http.Header{"X-Request-Deadline": {"500ms"}},
+			config:        json.RawMessage(`{"payload":"{}","deadline_header":"X-Request-Deadline"}`),
+		},
+		{
+			name: "deadline header with budget smaller than remote latency",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					select {
+					case <-r.Context().Done():
+					case <-time.After(500 * time.Millisecond):
+					}
+					w.WriteHeader(http.StatusOK)
+				}))
+			},
+			session:       &authn.AuthenticationSession{},
+			requestHeader: http.Header{"X-Request-Deadline": {"50ms"}},
+			config:        json.RawMessage(`{"payload":"{}","deadline_header":"X-Request-Deadline","retry":{"give_up_after":"10ms"}}`),
+			wantErr:       true,
+		},
+		{
+			name: "deadline header with exhausted budget",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					t.Error("remote must not be called when the budget is exhausted")
+					w.WriteHeader(http.StatusOK)
+				}))
+			},
+			session:       &authn.AuthenticationSession{},
+			requestHeader: http.Header{"X-Request-Deadline": {time.Now().Add(-time.Second).Format(time.RFC3339)}},
+			config:        json.RawMessage(`{"payload":"{}","deadline_header":"X-Request-Deadline"}`),
+			wantErr:       true,
+		},
+		{
+			name:          "deadline header malformed",
+			session:       &authn.AuthenticationSession{},
+			requestHeader: http.Header{"X-Request-Deadline": {"tomorrow"}},
+			config:        json.RawMessage(`{"remote":"http://host/path","payload":"{}","deadline_header":"X-Request-Deadline"}`),
+			wantErr:       true,
+		},
+		{
+			name: "method included",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusForbidden)
+				}))
+			},
+			session:       &authn.AuthenticationSession{},
+			requestMethod: http.MethodDelete,
+			config:        json.RawMessage(`{"payload":"{}","methods":["POST","put","DELETE"]}`),
+			wantErr:       true,
+		},
+		{
+			name: "method included case-insensitively",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusForbidden)
+				}))
+			},
+			session:       &authn.AuthenticationSession{},
+			requestMethod: http.MethodPut,
+			config:        json.RawMessage(`{"payload":"{}","methods":["POST","put","DELETE"]}`),
+			wantErr:       true,
+		},
+		{
+			name: "method excluded",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					t.Error("remote must not be called for excluded methods")
+					w.WriteHeader(http.StatusForbidden)
+				}))
+			},
+			session:       &authn.AuthenticationSession{},
+			requestMethod: http.MethodGet,
+			config:        json.RawMessage(`{"payload":"{}","methods":["POST","PUT","DELETE"]}`),
+		},
+		{
+			name: "options preflight bypassed by default",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					t.Error("remote must not be called for preflight requests")
+					w.WriteHeader(http.StatusForbidden)
+				}))
+			},
+			session:       &authn.AuthenticationSession{},
+			requestMethod: http.MethodOptions,
+			requestHeader: http.Header{"Access-Control-Request-Method": {"POST"}, "Origin": {"https://example.com"}},
+			config:        json.RawMessage(`{"payload":"{}"}`),
+		},
+		{
+			name: "options preflight bypass disabled",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusForbidden)
+				}))
+			},
+			session:       &authn.AuthenticationSession{},
+			requestMethod: http.MethodOptions,
+			config:        json.RawMessage(`{"payload":"{}","bypass_options":false}`),
+			wantErr:       true,
+		},
+		{
+			name: "head not bypassed by default",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusForbidden)
+				}))
+			},
+			session:       &authn.AuthenticationSession{},
+			requestMethod: http.MethodHead,
+			config:        json.RawMessage(`{"payload":"{}"}`),
+			wantErr:       true,
+		},
+		{
+			name: "head bypassed",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					t.Error("remote must not be called for bypassed HEAD requests")
+					w.WriteHeader(http.StatusForbidden)
+				}))
+			},
+			session:       &authn.AuthenticationSession{},
+			requestMethod: http.MethodHead,
+			config:        json.RawMessage(`{"payload":"{}","bypass_head":true}`),
+		},
+		{
+			name: "xxhash template cache",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					body, err := io.ReadAll(r.Body)
+					require.NoError(t, err)
+					assert.Equal(t, `{"subject":"alice"}`, string(body))
+					w.WriteHeader(http.StatusOK)
+				}))
+			},
+			session: &authn.AuthenticationSession{Subject: "alice"},
+			config:  json.RawMessage(`{"payload":"{\"subject\":\"{{ .Subject }}\"}","template_cache_hash":"xxhash"}`),
+		},
+		{
+			name:    "invalid template cache hash",
+			session: &authn.AuthenticationSession{},
+			config:  json.RawMessage(`{"remote":"http://host/path","payload":"{}","template_cache_hash":"md5"}`),
+			wantErr: true,
+		},
 		{
 			name: "json array",
 			setup: func(t *testing.T) *httptest.Server {
@@ -196,6 +467,39 @@ func TestAuthorizerRemoteJSONAuthorize(t *testing.T) {
 			session: &authn.AuthenticationSession{},
 			config:  json.RawMessage(`{"payload":"[\"foo\",\"bar\"]"}`),
 		},
+		{
+			name: "custom template delimiters",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					body, err := io.ReadAll(r.Body)
+					require.NoError(t, err)
+					assert.Equal(t, `{"subject":"alice","raw":"{{ .Subject }}"}`, string(body))
+					assert.Equal(t, "alice", r.Header.Get("X-Subject"))
+					w.WriteHeader(http.StatusOK)
+				}))
+			},
+			session: &authn.AuthenticationSession{Subject: "alice"},
+			config:  json.RawMessage(`{"payload":"{\"subject\":\"[[ .Subject ]]\",\"raw\":\"{{ .Subject }}\"}","headers":{"X-Subject":"[[ .Subject ]]"},"template_delimiters":{"left":"[[","right":"]]"}}`),
+		},
+		{
+			name: "custom template delimiters with strict templates",
+			setup: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					body, err := io.ReadAll(r.Body)
+					require.NoError(t, err)
+					assert.Equal(t, `{"subject":"alice"}`, string(body))
+					w.WriteHeader(http.StatusOK)
+				}))
+			},
+			session: &authn.AuthenticationSession{Subject: "alice"},
+			config:  json.RawMessage(`{"payload":"{\"subject\":\"[[ .Subject ]]\"}","strict_templates":true,"template_delimiters":{"left":"[[","right":"]]"}}`),
+		},
+		{
+			name:    "custom template delimiters with strict templates and unknown field",
+			session: &authn.AuthenticationSession{Subject: "alice"},
+			config:  json.RawMessage(`{"remote":"http://host/path","payload":"{\"subject\":\"[[ .Subjekt ]]\"}","strict_templates":true,"template_delimiters":{"left":"[[","right":"]]"}}`),
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -212,12 +516,15 @@ func TestAuthorizerRemoteJSONAuthorize(t *testing.T) {
 			if err != nil {
 				l.WithError(err).Fatal("Failed to initialize configuration")
 			}
-			a := NewAuthorizerRemoteJSON(p, otelx.NewNoop(l, p.TracingConfig()))
+			a := NewAuthorizerRemoteJSON(p, newRemoteJSONDependencies(l, p))
 			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 			defer cancel()
-			r, err := http.NewRequestWithContext(ctx, "", "", nil)
+			r, err := http.NewRequestWithContext(ctx, tt.requestMethod, "", nil)
 			require.NoError(t, err)
 			r.Header = map[string][]string{"Authorization": {"Bearer token"}}
+			for k, v := range tt.requestHeader {
+				r.Header[k] = v
+			}
 			if err := a.Authorize(r, tt.session, tt.config, &rule.Rule{}); (err != nil) != tt.wantErr {
 				t.Errorf("Authorize() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -276,6 +583,28 @@ func TestAuthorizerRemoteJSONValidate(t *testing.T) {
 			enabled: true,
 			config:  json.RawMessage(`{"remote":"http://host/path","payload":"{}","headers":{"Authorization":"Bearer token"}}`),
 		},
+		{
+			name:    "lenient templates with typo'd field",
+			enabled: true,
+			config:  json.RawMessage(`{"remote":"http://host/path","payload":"{}","headers":{"Subject":"{{ .Subjcet }}"}}`),
+		},
+		{
+			name:    "strict templates with typo'd field in headers",
+			enabled: true,
+			config:  json.RawMessage(`{"remote":"http://host/path","payload":"{}","strict_templates":true,"headers":{"Subject":"{{ .Subjcet }}"}}`),
+			wantErr: true,
+		},
+		{
+			name:    "strict templates with typo'd field in payload",
+			enabled: true,
+			config:  json.RawMessage(`{"remote":"http://host/path","payload":"{\"sub\":\"{{ .MatchContext.Methd }}\"}","strict_templates":true}`),
+			wantErr: true,
+		},
+		{
+			name:    "strict templates with valid fields",
+			enabled: true,
+			config:  json.RawMessage(`{"remote":"http://host/path","payload":"{\"sub\":\"{{ .Subject }}\",\"path\":\"{{ .MatchContext.URL.Path }}\"}","strict_templates":true,"headers":{"X-Tenant":"{{ .Extra.tenant }}"}}`),
+		},
 		{
 			name:    "valid configuration with partial retry 1",
 			enabled: true,
@@ -302,7 +631,7 @@ func TestAuthorizerRemoteJSONValidate(t *testing.T) {
 			)
 			require.NoError(t, err)
 			l := logrusx.New("", "")
-			a := NewAuthorizerRemoteJSON(p, otelx.NewNoop(l, p.TracingConfig()))
+			a := NewAuthorizerRemoteJSON(p, newRemoteJSONDependencies(l, p))
 			p.SetForTest(t, configuration.AuthorizerRemoteJSONIsEnabled, tt.enabled)
 			if err := a.Validate(tt.config); (err != nil) != tt.wantErr {
 				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
@@ -330,6 +659,18 @@ func TestAuthorizerRemoteJSONConfig(t *testing.T) {
 					Timeout: "100ms", // default timeout from schema
 					MaxWait: "1s",
 				},
+				DenialLogLevel:       "info",
+				LoadBalance:          "failover",
+				AsyncWorkers:         4,
+				AsyncQueueSize:       100,
+				AbstainStatusCodes:   []int{http.StatusNotFound},
+				AcceptStatusCodes:    []int{http.StatusOK},
+				ForbiddenStatusCodes: []int{http.StatusForbidden},
+				EmptyPayload:         "error",
+				MaxPayloadBytes:      1 << 20,
+				TimeoutStatusCode:    http.StatusServiceUnavailable,
+				Method:               http.MethodPost,
+				Timeout:              "1m",
 			},
 		},
 		{
@@ -343,6 +684,18 @@ func TestAuthorizerRemoteJSONConfig(t *testing.T) {
 					Timeout: "100ms", // default timeout from schema
 					MaxWait: "1s",
 				},
+				DenialLogLevel:       "info",
+				LoadBalance:          "failover",
+				AsyncWorkers:         4,
+				AsyncQueueSize:       100,
+				AbstainStatusCodes:   []int{http.StatusNotFound},
+				AcceptStatusCodes:    []int{http.StatusOK},
+				ForbiddenStatusCodes: []int{http.StatusForbidden},
+				EmptyPayload:         "error",
+				MaxPayloadBytes:      1 << 20,
+				TimeoutStatusCode:    http.StatusServiceUnavailable,
+				Method:               http.MethodPost,
+				Timeout:              "1m",
 			},
 		},
 	}
@@ -355,10 +708,2026 @@ func TestAuthorizerRemoteJSONConfig(t *testing.T) {
 			)
 			require.NoError(t, err)
 			l := logrusx.New("", "")
-			a := NewAuthorizerRemoteJSON(p, otelx.NewNoop(l, p.TracingConfig()))
+			a := NewAuthorizerRemoteJSON(p, newRemoteJSONDependencies(l, p))
 			actual, err := a.Config(tt.raw)
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expected, actual)
 		})
 	}
 }
+
+func TestAuthorizerRemoteJSONShutdown(t *testing.T) {
+	// Not parallel, so that goroutines of other tests are not reported as leaks.
+	l := logrusx.New("", "")
+	p, err := configuration.NewKoanfProvider(context.Background(), nil, l)
+	require.NoError(t, err)
+
+	t.Run("case=creates nothing after shutdown", func(t *testing.T) {
+		defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+		var calls atomic.Int64
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		a := NewAuthorizerRemoteJSON(p, newRemoteJSONDependencies(l, p))
+		var _ Shutdowner = a
+		require.NoError(t, a.Shutdown(context.Background()))
+
+		r, err := http.NewRequest("", "", nil)
+		require.NoError(t, err)
+		config, _ := sjson.SetBytes(json.RawMessage(`{"payload":"{}"}`), "remote", server.URL)
+		require.NoError(t, a.Authorize(r, &authn.AuthenticationSession{}, config, &rule.Rule{}), "requests still using the authorizer must be served")
+		cached, _ := sjson.SetBytes(json.RawMessage(`{"payload":"{}","cache":{"ttl":"1m"}}`), "remote", server.URL)
+		require.NoError(t, a.Authorize(r, &authn.AuthenticationSession{}, cached, &rule.Rule{}))
+		require.NoError(t, a.Authorize(r, &authn.AuthenticationSession{}, cached, &rule.Rule{}))
+		async, _ := sjson.SetBytes(json.RawMessage(`{"payload":"{}","async":true}`), "remote", server.URL)
+		require.NoError(t, a.Authorize(r, &authn.AuthenticationSession{}, async, &rule.Rule{}))
+
+		assert.EqualValues(t, 3, calls.Load(), "decisions must not be cached and asynchronous calls must be dropped")
+		require.NoError(t, a.Shutdown(context.Background()))
+	})
+
+	t.Run("case=releases its goroutines", func(t *testing.T) {
+		defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+		called := make(chan struct{}, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			select {
+			case called <- struct{}{}:
+			default:
+			}
+		}))
+		defer server.Close()
+
+		a := NewAuthorizerRemoteJSON(p, newRemoteJSONDependencies(l, p))
+
+		r, err := http.NewRequest("", "", nil)
+		require.NoError(t, err)
+		async, _ := sjson.SetBytes(json.RawMessage(`{"payload":"{}","async":true}`), "remote", server.URL)
+		require.NoError(t, a.Authorize(r, &authn.AuthenticationSession{}, async, &rule.Rule{}))
+		<-called
+
+		cached, _ := sjson.SetBytes(json.RawMessage(`{"payload":"{}","cache":{"ttl":"1m"}}`), "remote", server.URL)
+		require.NoError(t, a.Authorize(r, &authn.AuthenticationSession{}, cached, &rule.Rule{}))
+
+		require.NoError(t, a.Shutdown(context.Background()))
+	})
+}
+
+func TestAuthorizerRemoteJSONUnixSocket(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(t.TempDir(), "authz.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/authorize", r.URL.Path)
+		assert.Equal(t, "policy.local", r.Host)
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"subject":"alice"}`, string(body))
+		w.Header().Set("X-Foo", "bar")
+		w.WriteHeader(http.StatusOK)
+	}))
+	require.NoError(t, server.Listener.Close())
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	config, _ := sjson.SetBytes(json.RawMessage(`{"remote":"http://policy.local/authorize","payload":"{\"subject\":\"{{ .Subject }}\"}","forward_response_headers_to_upstream":["X-Foo"]}`), "unix_socket", socket)
+	r, err := http.NewRequest("", "", nil)
+	require.NoError(t, err)
+	session := &authn.AuthenticationSession{Subject: "alice"}
+	require.NoError(t, a.Authorize(r, session, config, &rule.Rule{}))
+	assert.Equal(t, "bar", session.Header.Get("X-Foo"))
+}
+
+func TestAuthorizerRemoteJSONUnixSocketConcurrentRules(t *testing.T) {
+	t.Parallel()
+
+	via := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("X-Via", name)
+			w.WriteHeader(http.StatusOK)
+		})
+	}
+
+	socket := filepath.Join(t.TempDir(), "authz.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	overSocket := httptest.NewUnstartedServer(via("socket"))
+	require.NoError(t, overSocket.Listener.Close())
+	overSocket.Listener = listener
+	overSocket.Start()
+	defer overSocket.Close()
+
+	overTCP := httptest.NewServer(via("tcp"))
+	defer overTCP.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	tcpConfig := json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{\"subject\":\"{{ .Subject }}\"}","forward_response_headers_to_upstream":["X-Via"]}`, overTCP.URL))
+	socketConfig, _ := sjson.SetBytes(tcpConfig, "unix_socket", socket)
+
+	var wg sync.WaitGroup
+	for k := 0; k < 50; k++ {
+		for expected, config := range map[string]json.RawMessage{"tcp": tcpConfig, "socket": socketConfig} {
+			wg.Add(1)
+			go func(k int, expected string, config json.RawMessage) {
+				defer wg.Done()
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				// Both rules send the same payload to the same remote, so only
+				// the routing settings tell their shared calls apart.
+				session := &authn.AuthenticationSession{Subject: fmt.Sprintf("%d", k)}
+				if assert.NoError(t, a.Authorize(r, session, config, &rule.Rule{})) {
+					assert.Equal(t, expected, session.Header.Get("X-Via"))
+				}
+			}(k, expected, config)
+		}
+	}
+	wg.Wait()
+}
+
+func TestAuthorizerRemoteJSONTemplateCache(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get("X-Subject")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+	rl := &rule.Rule{ID: "test-rule"}
+	session := &authn.AuthenticationSession{Subject: "alice"}
+
+	for _, tc := range []struct {
+		header string
+		want   string
+	}{
+		{header: "{{ .Subject }}", want: "alice"},
+		{header: "static", want: "static"},
+		{header: "{{ .Subject }}", want: "alice"},
+	} {
+		config, _ := sjson.SetBytes(json.RawMessage(`{"payload":"{}"}`), "remote", server.URL)
+		config, _ = sjson.SetBytes(config, "headers.X-Subject", tc.header)
+		r, err := http.NewRequest("", "", nil)
+		require.NoError(t, err)
+		require.NoError(t, a.Authorize(r, session, config, rl))
+		assert.Equal(t, tc.want, <-received, "a changed template with the same ID must not reuse the cached template")
+	}
+
+	t.Run("case=colliding IDs are parsed concurrently", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload struct {
+				Value string `json:"value"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Value != r.Header.Get("X-Value") {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		var wg sync.WaitGroup
+		for k := 0; k < 8; k++ {
+			config, _ := sjson.SetBytes(json.RawMessage(`{}`), "remote", server.URL)
+			config, _ = sjson.SetBytes(config, "payload", fmt.Sprintf(`{"value":"%d"}`, k))
+			config, _ = sjson.SetBytes(config, "headers.X-Value", fmt.Sprintf(`{{ print "%d" }}`, k))
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					r := httptest.NewRequest(http.MethodGet, "/", nil)
+					assert.NoError(t, a.Authorize(r, &authn.AuthenticationSession{Subject: "alice"}, config, rl))
+				}()
+			}
+		}
+		wg.Wait()
+	})
+}
+
+func BenchmarkPayloadTemplateID(b *testing.B) {
+	payload := `{"subject":"{{ .Subject }}","resource":"{{ printIndex .MatchContext.RegexpCaptureGroups 0 }}","extra":{{ .Extra | toJson }}}`
+	for _, hash := range []string{"sha256", "xxhash"} {
+		c := &AuthorizerRemoteJSONConfiguration{Payload: payload, TemplateCacheHash: hash}
+		b.Run("hash="+hash, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = c.PayloadTemplateID()
+			}
+		})
+	}
+}
+
+func TestAuthorizerRemoteJSONWWWAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Realm", "api")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"scope":"write","user_id":9007199254740993}`))
+	}))
+	defer server.Close()
+
+	for _, tc := range []struct {
+		name    string
+		config  string
+		want    string
+		noValue bool
+	}{
+		{
+			name:    "not configured",
+			config:  `{"payload":"{}"}`,
+			noValue: true,
+		},
+		{
+			name:   "static",
+			config: `{"payload":"{}","www_authenticate":"Bearer error=\"insufficient_scope\""}`,
+			want:   `Bearer error="insufficient_scope"`,
+		},
+		{
+			name:   "templated from response",
+			config: `{"payload":"{}","www_authenticate":"Bearer realm=\"{{ .Header.Get \"X-Realm\" }}\", error=\"insufficient_scope\", scope=\"{{ .Body.scope }}\", sub=\"{{ .Session.Subject }}\""}`,
+			want:   `Bearer realm="api", error="insufficient_scope", scope="write", sub="alice"`,
+		},
+		{
+			name:   "large integers are preserved",
+			config: `{"payload":"{}","www_authenticate":"Bearer user_id=\"{{ .Body.user_id }}\""}`,
+			want:   `Bearer user_id="9007199254740993"`,
+		},
+		{
+			name:    "templated to empty",
+			config:  `{"payload":"{}","www_authenticate":"{{ if .Body.error }}Bearer{{ end }}"}`,
+			noValue: true,
+		},
+	} {
+		tc := tc
+		t.Run("case="+tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			a, _ := newTestAuthorizerRemoteJSON(t)
+
+			config, _ := sjson.SetBytes(json.RawMessage(tc.config), "remote", server.URL)
+			r, err := http.NewRequest("", "", nil)
+			require.NoError(t, err)
+			err = a.Authorize(r, &authn.AuthenticationSession{Subject: "alice"}, config, &rule.Rule{ID: tc.name})
+			require.ErrorIs(t, err, helper.ErrForbidden)
+
+			var withHeader *helper.ErrWithHeader
+			if tc.noValue {
+				assert.False(t, errors.As(err, &withHeader))
+				return
+			}
+			require.True(t, errors.As(err, &withHeader))
+			assert.Equal(t, tc.want, withHeader.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
+
+func TestAuthorizerRemoteJSONResponseStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		status int
+		config string
+		extra  map[string]interface{}
+	}{
+		{
+			name:   "200",
+			status: http.StatusOK,
+			config: `{"payload":"{}","response_status":{"key":"remote_json","header":"X-Policy"}}`,
+			extra:  map[string]interface{}{"remote_json": map[string]interface{}{"status_code": http.StatusOK, "header": "none"}},
+		},
+		{
+			name:   "204 accepted by configuration",
+			status: http.StatusNoContent,
+			config: `{"payload":"{}","accept_status_codes":[200,204],"response_status":{"key":"remote_json","header":"X-Policy"}}`,
+			extra:  map[string]interface{}{"remote_json": map[string]interface{}{"status_code": http.StatusNoContent, "header": "none"}},
+		},
+		{
+			name:   "without header",
+			status: http.StatusOK,
+			config: `{"payload":"{}","response_status":{"key":"remote_json"}}`,
+			extra:  map[string]interface{}{"remote_json": map[string]interface{}{"status_code": http.StatusOK}},
+		},
+		{
+			name:   "not configured",
+			status: http.StatusOK,
+			config: `{"payload":"{}"}`,
+		},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("X-Policy", "none")
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			a, _ := newTestAuthorizerRemoteJSON(t)
+
+			config, err := sjson.SetBytes([]byte(tc.config), "remote", server.URL)
+			require.NoError(t, err)
+			r, err := http.NewRequest(http.MethodGet, "", nil)
+			require.NoError(t, err)
+
+			session := new(authn.AuthenticationSession)
+			require.NoError(t, a.Authorize(r, session, config, &rule.Rule{}))
+			assert.Equal(t, tc.extra, session.Extra)
+		})
+	}
+}
+
+func TestAuthorizerRemoteJSONProbe(t *testing.T) {
+	newAuthorizer := func(t *testing.T, healthCheck map[string]interface{}, opts ...configx.OptionModifier) *AuthorizerRemoteJSON {
+		l := logrusx.New("", "")
+		if healthCheck != nil {
+			opts = append(opts, configx.WithValue("authorizers.remote_json.config.health_check", healthCheck))
+		}
+		p, err := configuration.NewKoanfProvider(context.Background(), nil, l, opts...)
+		require.NoError(t, err)
+		a := NewAuthorizerRemoteJSON(p, newRemoteJSONDependencies(l, p))
+		var _ Prober = a
+		return a
+	}
+
+	t.Run("case=not configured", func(t *testing.T) {
+		require.NoError(t, newAuthorizer(t, nil).Probe(context.Background()))
+	})
+
+	t.Run("case=http", func(t *testing.T) {
+		status := http.StatusOK
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/health/ready", r.URL.Path)
+			w.WriteHeader(status)
+		}))
+		defer server.Close()
+
+		a := newAuthorizer(t, map[string]interface{}{"endpoint": server.URL + "/health/ready"})
+		require.NoError(t, a.Probe(context.Background()))
+
+		status = http.StatusServiceUnavailable
+		require.Error(t, a.Probe(context.Background()))
+	})
+
+	t.Run("case=http unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		a := newAuthorizer(t, map[string]interface{}{"type": "http", "endpoint": server.URL})
+		require.Error(t, a.Probe(context.Background()))
+	})
+
+	t.Run("case=http uses the client profile", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		a := newAuthorizer(t, map[string]interface{}{"endpoint": server.URL})
+		require.Error(t, a.Probe(context.Background()), "the self-signed certificate must not be trusted by default")
+
+		a = newAuthorizer(t, map[string]interface{}{"endpoint": server.URL},
+			configx.WithValue(configuration.HTTPClientProfiles+".insecure", map[string]interface{}{"tls": map[string]interface{}{"insecure_skip_verify": true}}),
+			configx.WithValue("authorizers.remote_json.config.client_profile", "insecure"),
+		)
+		require.NoError(t, a.Probe(context.Background()))
+	})
+
+	t.Run("case=http stalled", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		a := newAuthorizer(t, map[string]interface{}{"endpoint": server.URL}, configx.WithValue("authorizers.remote_json.config.timeout", "50ms"))
+		start := time.Now()
+		require.ErrorIs(t, a.Probe(context.Background()), context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("case=grpc", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		hs := health.NewServer()
+		s := grpc.NewServer()
+		grpc_health_v1.RegisterHealthServer(s, hs)
+		go s.Serve(listener) //nolint:errcheck
+		defer s.Stop()
+
+		hs.SetServingStatus("authz", grpc_health_v1.HealthCheckResponse_SERVING)
+		a := newAuthorizer(t, map[string]interface{}{"type": "grpc", "endpoint": listener.Addr().String(), "service": "authz"})
+		require.NoError(t, a.Probe(context.Background()))
+
+		hs.SetServingStatus("authz", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		require.Error(t, a.Probe(context.Background()))
+
+		a = newAuthorizer(t, map[string]interface{}{"type": "grpc", "endpoint": listener.Addr().String(), "service": "unknown"})
+		require.Error(t, a.Probe(context.Background()))
+	})
+}
+
+func TestAuthorizerRemoteJSONLogging(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config string
+		closed bool
+		level  logrus.Level
+		event  string
+	}{
+		{name: "transport error", config: `{"payload":"{}"}`, closed: true, level: logrus.WarnLevel, event: "remote_json_transport_error"},
+		{name: "denial", config: `{"payload":"{}"}`, level: logrus.InfoLevel, event: "remote_json_denied"},
+		{name: "denial at debug level", config: `{"payload":"{}","denial_log_level":"debug"}`, level: logrus.DebugLevel, event: "remote_json_denied"},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			}))
+			defer server.Close()
+			if tc.closed {
+				server.Close()
+			}
+
+			hook := new(test.Hook)
+			l := logrusx.New("", "", logrusx.WithHook(hook), logrusx.ForceLevel(logrus.TraceLevel))
+			p, err := configuration.NewKoanfProvider(context.Background(), nil, l)
+			require.NoError(t, err)
+			a := NewAuthorizerRemoteJSON(p, newRemoteJSONDependencies(l, p))
+
+			config, err := sjson.SetBytes([]byte(tc.config), "remote", server.URL)
+			require.NoError(t, err)
+			r, err := http.NewRequest(http.MethodGet, "", nil)
+			require.NoError(t, err)
+			require.Error(t, a.Authorize(r, new(authn.AuthenticationSession), config, &rule.Rule{ID: "test-rule"}))
+
+			var entry *logrus.Entry
+			for _, e := range hook.AllEntries() {
+				if e.Data["event"] == tc.event {
+					entry = e
+				}
+			}
+			require.NotNil(t, entry, "expected a log entry for event %s", tc.event)
+			assert.Equal(t, tc.level, entry.Level)
+			assert.Equal(t, "test-rule", entry.Data["rule_id"])
+			assert.Equal(t, strings.TrimPrefix(server.URL, "http://"), entry.Data["remote_host"])
+			if tc.closed {
+				assert.Contains(t, entry.Data, logrus.ErrorKey)
+			} else {
+				assert.NotContains(t, entry.Data, logrus.ErrorKey)
+			}
+		})
+	}
+}
+
+func TestAuthorizerRemoteJSONEndpoints(t *testing.T) {
+	remotes := []AuthorizerRemoteJSONEndpoint{{URL: "http://a", Weight: 3}, {URL: "http://b"}, {URL: "http://c"}}
+	noRandom := func(int) int { t.Fatal("unexpected call to rnd"); return 0 }
+
+	t.Run("case=single remote", func(t *testing.T) {
+		c := &AuthorizerRemoteJSONConfiguration{Remote: "http://remote"}
+		assert.Equal(t, []string{"http://remote"}, c.Endpoints(5, noRandom))
+	})
+
+	t.Run("case=failover", func(t *testing.T) {
+		c := &AuthorizerRemoteJSONConfiguration{Remotes: remotes}
+		assert.Equal(t, []string{"http://a", "http://b", "http://c"}, c.Endpoints(5, noRandom))
+	})
+
+	t.Run("case=round_robin", func(t *testing.T) {
+		c := &AuthorizerRemoteJSONConfiguration{Remotes: remotes, LoadBalance: "round_robin"}
+		assert.Equal(t, []string{"http://a", "http://b", "http://c"}, c.Endpoints(0, noRandom))
+		assert.Equal(t, []string{"http://b", "http://a", "http://c"}, c.Endpoints(1, noRandom))
+		assert.Equal(t, []string{"http://c", "http://a", "http://b"}, c.Endpoints(5, noRandom))
+	})
+
+	t.Run("case=random", func(t *testing.T) {
+		c := &AuthorizerRemoteJSONConfiguration{Remotes: remotes, LoadBalance: "random"}
+		assert.Equal(t, []string{"http://c", "http://a", "http://b"}, c.Endpoints(0, func(max int) int {
+			assert.Equal(t, 3, max)
+			return 2
+		}))
+	})
+
+	t.Run("case=weighted", func(t *testing.T) {
+		c := &AuthorizerRemoteJSONConfiguration{Remotes: remotes, LoadBalance: "weighted"}
+		counts := map[string]int{}
+		for pick := 0; pick < 5; pick++ {
+			endpoints := c.Endpoints(0, func(max int) int {
+				assert.Equal(t, 5, max)
+				return pick
+			})
+			assert.Len(t, endpoints, 3)
+			counts[endpoints[0]]++
+		}
+		assert.Equal(t, map[string]int{"http://a": 3, "http://b": 1, "http://c": 1}, counts)
+	})
+}
+
+func TestAuthorizerRemoteJSONLoadBalance(t *testing.T) {
+	newServer := func(t *testing.T, calls *atomic.Int64) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, `{"subject":"alice"}`, string(body))
+			calls.Add(1)
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	authorize := func(t *testing.T, config string, requests int) {
+		a, _ := newTestAuthorizerRemoteJSON(t)
+
+		for i := 0; i < requests; i++ {
+			r, err := http.NewRequest(http.MethodGet, "", nil)
+			require.NoError(t, err)
+			require.NoError(t, a.Authorize(r, &authn.AuthenticationSession{Subject: "alice"}, json.RawMessage(config), &rule.Rule{}))
+		}
+	}
+
+	t.Run("case=round_robin", func(t *testing.T) {
+		var first, second, third atomic.Int64
+		config := fmt.Sprintf(`{"payload":"{\"subject\":\"{{ .Subject }}\"}","load_balance":"round_robin","remotes":[{"url":"%s"},{"url":"%s"},{"url":"%s"}]}`,
+			newServer(t, &first).URL, newServer(t, &second).URL, newServer(t, &third).URL)
+
+		authorize(t, config, 30)
+		assert.EqualValues(t, 10, first.Load())
+		assert.EqualValues(t, 10, second.Load())
+		assert.EqualValues(t, 10, third.Load())
+	})
+
+	t.Run("case=weighted", func(t *testing.T) {
+		var heavy, light atomic.Int64
+		config := fmt.Sprintf(`{"payload":"{\"subject\":\"{{ .Subject }}\"}","load_balance":"weighted","remotes":[{"url":"%s","weight":3},{"url":"%s","weight":1}]}`,
+			newServer(t, &heavy).URL, newServer(t, &light).URL)
+
+		authorize(t, config, 400)
+		assert.EqualValues(t, 400, heavy.Load()+light.Load())
+		// The expected share is 300, the bounds are more than six standard deviations apart from it.
+		assert.InDelta(t, 300, heavy.Load(), 60)
+	})
+
+	t.Run("case=fails over on transport errors", func(t *testing.T) {
+		var first, second atomic.Int64
+		unreachable := newServer(t, &first)
+		unreachable.Close()
+		config := fmt.Sprintf(`{"payload":"{\"subject\":\"{{ .Subject }}\"}","load_balance":"round_robin","retry":{"give_up_after":"10ms","max_delay":"5ms"},"remotes":[{"url":"%s"},{"url":"%s"}]}`,
+			unreachable.URL, newServer(t, &second).URL)
+
+		authorize(t, config, 4)
+		assert.EqualValues(t, 0, first.Load())
+		assert.EqualValues(t, 4, second.Load())
+	})
+
+	t.Run("case=fails if no remote can be reached", func(t *testing.T) {
+		var calls atomic.Int64
+		unreachable := newServer(t, &calls)
+		unreachable.Close()
+
+		a, _ := newTestAuthorizerRemoteJSON(t)
+
+		r, err := http.NewRequest(http.MethodGet, "", nil)
+		require.NoError(t, err)
+		config := fmt.Sprintf(`{"payload":"{}","retry":{"give_up_after":"10ms","max_delay":"5ms"},"remotes":[{"url":"%s"},{"url":"%s"}]}`, unreachable.URL, unreachable.URL)
+		require.Error(t, a.Authorize(r, new(authn.AuthenticationSession), json.RawMessage(config), &rule.Rule{}))
+	})
+}
+
+func TestAuthorizerRemoteJSONResolve(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/authorize", r.URL.Path)
+		w.Header().Set("X-Host", r.Host)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	for _, tc := range []struct {
+		name    string
+		resolve string
+	}{
+		{name: "by host", resolve: `{"policy.invalid":"127.0.0.1"}`},
+		{name: "by host and port", resolve: fmt.Sprintf(`{"policy.invalid:%s":"127.0.0.1"}`, port)},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			config := fmt.Sprintf(`{"remote":"http://policy.invalid:%s/authorize","payload":"{}","forward_response_headers_to_upstream":["X-Host"],"resolve":%s}`, port, tc.resolve)
+			r, err := http.NewRequest("", "", nil)
+			require.NoError(t, err)
+			session := new(authn.AuthenticationSession)
+			require.NoError(t, a.Authorize(r, session, json.RawMessage(config), &rule.Rule{}))
+			assert.Equal(t, "policy.invalid:"+port, session.Header.Get("X-Host"))
+		})
+	}
+
+	t.Run("case=invalid ip", func(t *testing.T) {
+		_, err := a.Config(json.RawMessage(`{"remote":"http://policy.invalid/authorize","payload":"{}","resolve":{"policy.invalid":"localhost"}}`))
+		require.Error(t, err)
+	})
+
+	t.Run("case=combined with unix socket", func(t *testing.T) {
+		_, err := a.Config(json.RawMessage(`{"remote":"http://policy.invalid/authorize","payload":"{}","unix_socket":"/tmp/authz.sock","resolve":{"policy.invalid":"127.0.0.1"}}`))
+		require.Error(t, err)
+	})
+}
+
+func TestAuthorizerRemoteJSONAsync(t *testing.T) {
+	received := make(chan string, 10)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received <- string(body)
+		<-release
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	newAuthorizer := func(t *testing.T) (*AuthorizerRemoteJSON, *remoteJSONDependencies) {
+		l := logrusx.New("", "")
+		p, err := configuration.NewKoanfProvider(context.Background(), nil, l)
+		require.NoError(t, err)
+		d := newRemoteJSONDependencies(l, p)
+		return NewAuthorizerRemoteJSON(p, d), d
+	}
+	authorize := func(t *testing.T, a *AuthorizerRemoteJSON, subject string) {
+		config, _ := sjson.SetBytes(json.RawMessage(`{"payload":"{\"subject\":\"{{ .Subject }}\"}","async":true,"async_workers":1,"async_queue_size":1}`), "remote", server.URL)
+		r, err := http.NewRequest(http.MethodGet, "", nil)
+		require.NoError(t, err)
+		require.NoError(t, a.Authorize(r, &authn.AuthenticationSession{Subject: subject}, config, &rule.Rule{}))
+	}
+
+	t.Run("case=allows without waiting for the remote", func(t *testing.T) {
+		a, _ := newAuthorizer(t)
+		authorize(t, a, "alice")
+		assert.Equal(t, `{"subject":"alice"}`, <-received)
+
+		release <- struct{}{}
+		require.NoError(t, a.Shutdown(context.Background()))
+	})
+
+	t.Run("case=drops calls if the queue is full", func(t *testing.T) {
+		a, d := newAuthorizer(t)
+
+		authorize(t, a, "alice")
+		assert.Equal(t, `{"subject":"alice"}`, <-received)
+		authorize(t, a, "bob")
+		authorize(t, a, "carol")
+		assert.EqualValues(t, 1, testutil.ToFloat64(d.m.AsyncDroppedTotal))
+
+		release <- struct{}{}
+		assert.Equal(t, `{"subject":"bob"}`, <-received)
+		release <- struct{}{}
+		require.NoError(t, a.Shutdown(context.Background()))
+		assert.Empty(t, received)
+	})
+
+	t.Run("case=shutdown gives up waiting for queued calls", func(t *testing.T) {
+		a, _ := newAuthorizer(t)
+		authorize(t, a, "alice")
+		assert.Equal(t, `{"subject":"alice"}`, <-received)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, a.Shutdown(ctx), context.DeadlineExceeded)
+		release <- struct{}{}
+	})
+
+	t.Run("case=gives up on calls after the timeout", func(t *testing.T) {
+		canceled := make(chan struct{}, 1)
+		hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			canceled <- struct{}{}
+		}))
+		defer hung.Close()
+
+		a, _ := newAuthorizer(t)
+		config, _ := sjson.SetBytes(json.RawMessage(`{"payload":"{}","async":true,"async_workers":1,"timeout":"50ms"}`), "remote", hung.URL)
+		r, err := http.NewRequest(http.MethodGet, "", nil)
+		require.NoError(t, err)
+		require.NoError(t, a.Authorize(r, new(authn.AuthenticationSession), config, &rule.Rule{}))
+
+		select {
+		case <-canceled:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the asynchronous call was not bounded by the timeout")
+		}
+		require.NoError(t, a.Shutdown(context.Background()))
+	})
+}
+
+func TestAuthorizerRemoteJSONClientProfile(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	a, p := newTestAuthorizerRemoteJSON(t)
+	p.SetForTest(t, configuration.HTTPClientProfiles+".fast", map[string]interface{}{
+		"timeout":     "500ms",
+		"max_retries": 1,
+		"retry":       map[string]interface{}{"max_delay": "5ms", "give_up_after": "50ms"},
+		"tls":         map[string]interface{}{"insecure_skip_verify": true},
+	})
+
+	for _, tc := range []struct {
+		name        string
+		config      string
+		maxDelay    string
+		giveUpAfter string
+		expectErr   bool
+	}{
+		{
+			name:        "profile resolved",
+			config:      `{"remote":"%s","payload":"{}","client_profile":"fast"}`,
+			maxDelay:    "5ms",
+			giveUpAfter: "50ms",
+		},
+		{
+			name:        "inline retry takes precedence",
+			config:      `{"remote":"%s","payload":"{}","client_profile":"fast","retry":{"max_delay":"10ms"}}`,
+			maxDelay:    "10ms",
+			giveUpAfter: "50ms",
+		},
+		{
+			name:      "unknown profile",
+			config:    `{"remote":"%s","payload":"{}","client_profile":"slow"}`,
+			expectErr: true,
+		},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			c, err := a.Config(json.RawMessage(fmt.Sprintf(tc.config, server.URL)))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.maxDelay, c.Retry.Timeout)
+			assert.Equal(t, tc.giveUpAfter, c.Retry.MaxWait)
+
+			// The profile skips verification of the self-signed certificate of the server.
+			r, err := http.NewRequest("", "", nil)
+			require.NoError(t, err)
+			require.NoError(t, a.Authorize(r, new(authn.AuthenticationSession), json.RawMessage(fmt.Sprintf(tc.config, server.URL)), &rule.Rule{}))
+		})
+	}
+
+	t.Run("case=without profile", func(t *testing.T) {
+		r, err := http.NewRequest("", "", nil)
+		require.NoError(t, err)
+		require.Error(t, a.Authorize(r, new(authn.AuthenticationSession), json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}"}`, server.URL)), &rule.Rule{}))
+	})
+}
+
+func TestAuthorizerRemoteJSONAbstain(t *testing.T) {
+	t.Parallel()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	for _, tc := range []struct {
+		name    string
+		status  int
+		abstain string
+		expect  bool
+	}{
+		{name: "not found abstains by default", status: http.StatusNotFound, expect: true},
+		{name: "configured status abstains", status: http.StatusConflict, abstain: `,"abstain_status_codes":[409]`, expect: true},
+		{name: "not found without abstain status codes", status: http.StatusNotFound, abstain: `,"abstain_status_codes":[]`},
+		{name: "forbidden does not abstain", status: http.StatusForbidden},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			r, err := http.NewRequest("", "", nil)
+			require.NoError(t, err)
+			err = a.Authorize(r, new(authn.AuthenticationSession), json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}"%s}`, server.URL, tc.abstain)), &rule.Rule{})
+			require.Error(t, err)
+			assert.Equal(t, tc.expect, errors.Is(err, ErrAuthorizerNotResponsible), "%+v", err)
+		})
+	}
+}
+
+func TestAuthorizerRemoteJSONTrailer(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"digest":"sha-256=abc"}`, string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	// A threshold of 2 bytes spools the body to a temporary file.
+	for _, threshold := range []int{0, 2} {
+		t.Run(fmt.Sprintf("body_spool_threshold=%d", threshold), func(t *testing.T) {
+			r, err := http.ReadRequest(bufio.NewReader(strings.NewReader("POST /upload HTTP/1.1\r\n" +
+				"Host: example.com\r\n" +
+				"Transfer-Encoding: chunked\r\n" +
+				"Trailer: Digest\r\n" +
+				"\r\n" +
+				"5\r\nhello\r\n" +
+				"0\r\n" +
+				"Digest: sha-256=abc\r\n" +
+				"\r\n")))
+			require.NoError(t, err)
+			assert.Empty(t, r.Trailer.Get("Digest"))
+
+			session := new(authn.AuthenticationSession)
+			config := fmt.Sprintf(`{"remote":"%s","payload":"{\"digest\":\"{{ .MatchContext.Trailer.Get \"Digest\" }}\"}","body_spool_threshold":%d}`, server.URL, threshold)
+			require.NoError(t, a.Authorize(r, session, json.RawMessage(config), &rule.Rule{}))
+
+			// The body is still available to the upstream.
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, "hello", string(body))
+			require.NoError(t, r.Body.Close())
+		})
+	}
+}
+
+func TestAuthorizerRemoteJSONDecisionEvent(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		status  int
+		outcome string
+	}{
+		{status: http.StatusOK, outcome: "allow"},
+		{status: http.StatusForbidden, outcome: "deny"},
+		{status: http.StatusNotFound, outcome: "abstain"},
+		{status: http.StatusTeapot, outcome: "error"},
+	} {
+		t.Run("outcome="+tc.outcome, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			l := logrusx.New("", "")
+			p, err := configuration.NewKoanfProvider(context.Background(), nil, l)
+			require.NoError(t, err)
+			a := NewAuthorizerRemoteJSON(p, &remoteJSONDependencies{l: l, t: tp.Tracer("test"), m: NewRemoteJSONMetrics("")})
+			defer a.Shutdown(context.Background()) //nolint:errcheck
+
+			r, err := http.NewRequest("", "", nil)
+			require.NoError(t, err)
+			_ = a.Authorize(r, new(authn.AuthenticationSession), json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}"}`, server.URL)), &rule.Rule{})
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			require.Len(t, spans[0].Events(), 1)
+			event := spans[0].Events()[0]
+			assert.Equal(t, "authz.decision", event.Name)
+
+			attrs := map[string]interface{}{}
+			for _, kv := range event.Attributes {
+				attrs[string(kv.Key)] = kv.Value.AsInterface()
+			}
+			assert.Equal(t, map[string]interface{}{
+				"authz.outcome":             tc.outcome,
+				"authz.retries":             int64(0),
+				"http.response.status_code": int64(tc.status),
+				"server.address":            strings.TrimPrefix(server.URL, "http://"),
+			}, attrs)
+		})
+	}
+}
+
+func TestAuthorizerRemoteJSONEmptyPayload(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "{}", string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	for _, tc := range []struct {
+		mode      string
+		expectErr bool
+		calls     int32
+	}{
+		{mode: "", expectErr: true},
+		{mode: "error", expectErr: true},
+		{mode: "empty_object", calls: 1},
+		{mode: "allow"},
+	} {
+		t.Run("mode="+tc.mode, func(t *testing.T) {
+			calls.Store(0)
+			config, err := sjson.Set(fmt.Sprintf(`{"remote":"%s","payload":"{{ .Subject }} "}`, server.URL), "empty_payload", tc.mode)
+			require.NoError(t, err)
+			if tc.mode == "" {
+				config, err = sjson.Delete(config, "empty_payload")
+				require.NoError(t, err)
+			}
+
+			r, err := http.NewRequest("", "", nil)
+			require.NoError(t, err)
+			err = a.Authorize(r, new(authn.AuthenticationSession), json.RawMessage(config), &rule.Rule{ID: "empty"})
+			if tc.expectErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), `rule "empty"`)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.calls, calls.Load())
+		})
+	}
+}
+
+func TestAuthorizerRemoteJSONPayloadFile(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"subject":"alice"}`, string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "payload.json.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"subject":"{{ .Subject }}"}`), 0o600))
+
+	a, p := newTestAuthorizerRemoteJSON(t)
+	p.SetForTest(t, configuration.AuthorizerRemoteJSONPayloadDirs, []string{dir})
+
+	t.Run("case=loads the template from the file", func(t *testing.T) {
+		config := fmt.Sprintf(`{"remote":"%s","payload":"file://%s"}`, server.URL, path)
+		c, err := a.Config(json.RawMessage(config))
+		require.NoError(t, err)
+		assert.Equal(t, `{"subject":"{{ .Subject }}"}`, c.Payload)
+
+		r, err := http.NewRequest("", "", nil)
+		require.NoError(t, err)
+		require.NoError(t, a.Authorize(r, &authn.AuthenticationSession{Subject: "alice"}, json.RawMessage(config), &rule.Rule{}))
+	})
+
+	t.Run("case=missing file", func(t *testing.T) {
+		_, err := a.Config(json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"file://%s"}`, server.URL, filepath.Join(dir, "missing"))))
+		require.Error(t, err)
+	})
+
+	t.Run("case=rejects files outside of the payload directories", func(t *testing.T) {
+		outside := filepath.Join(filepath.Dir(dir), "secret.json")
+		require.NoError(t, os.WriteFile(outside, []byte(`{"secret":true}`), 0o600))
+		link := filepath.Join(dir, "link.json")
+		require.NoError(t, os.Symlink(outside, link))
+
+		for _, path := range []string{outside, dir + "/../secret.json", link} {
+			_, err := a.Config(json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"file://%s"}`, server.URL, path)))
+			require.Error(t, err, path)
+			assert.NotContains(t, fmt.Sprintf("%+v", err), `"secret":true`, path)
+		}
+	})
+
+	t.Run("case=reads the file again after a reload", func(t *testing.T) {
+		path := filepath.Join(dir, "reloaded.json.tmpl")
+		require.NoError(t, os.WriteFile(path, []byte(`{"version":1}`), 0o600))
+		config := json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"file://%s"}`, server.URL, path))
+
+		c, err := a.Config(config)
+		require.NoError(t, err)
+		assert.Equal(t, `{"version":1}`, c.Payload)
+
+		require.NoError(t, os.WriteFile(path, []byte(`{"version":2}`), 0o600))
+		c, err = a.Config(config)
+		require.NoError(t, err)
+		assert.Equal(t, `{"version":1}`, c.Payload, "the file is only read once")
+
+		var _ Reloader = a
+		a.Reload()
+		c, err = a.Config(config)
+		require.NoError(t, err)
+		assert.Equal(t, `{"version":2}`, c.Payload)
+	})
+}
+
+func TestAuthorizerRemoteJSONMaxPayloadBytes(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	session := &authn.AuthenticationSession{Subject: strings.Repeat("a", 64)}
+	for _, tc := range []struct {
+		name      string
+		max       string
+		expectErr bool
+	}{
+		{name: "default limit", max: ""},
+		{name: "oversized payload", max: `,"max_payload_bytes":32`, expectErr: true},
+		{name: "payload within limit", max: `,"max_payload_bytes":128`},
+		{name: "disabled limit", max: `,"max_payload_bytes":0`},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			calls.Store(0)
+			r, err := http.NewRequest("", "", nil)
+			require.NoError(t, err)
+			config := fmt.Sprintf(`{"remote":"%s","payload":"{\"subject\":\"{{ .Subject }}\"}"%s}`, server.URL, tc.max)
+			err = a.Authorize(r, session, json.RawMessage(config), &rule.Rule{ID: "large"})
+			if tc.expectErr {
+				require.ErrorContains(t, err, `rule "large"`)
+				assert.Zero(t, calls.Load())
+				return
+			}
+			require.NoError(t, err)
+			assert.EqualValues(t, 1, calls.Load())
+		})
+	}
+}
+
+func TestAuthorizerRemoteJSONRetryableDenial(t *testing.T) {
+	t.Parallel()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	for _, tc := range []struct {
+		name       string
+		status     int
+		header     string
+		body       string
+		retryAfter string
+		expectCode int
+	}{
+		{name: "retry after from header", status: http.StatusLocked, header: "5", expectCode: http.StatusTooManyRequests, retryAfter: "5"},
+		{name: "retry after from body", status: http.StatusLocked, body: `{"retry_after":5}`, expectCode: http.StatusTooManyRequests, retryAfter: "5"},
+		{name: "header takes precedence", status: http.StatusLocked, header: "3", body: `{"retry_after":5}`, expectCode: http.StatusTooManyRequests, retryAfter: "3"},
+		{name: "without retry after", status: http.StatusLocked, body: `{}`, expectCode: http.StatusTooManyRequests},
+		{name: "other denials stay forbidden", status: http.StatusForbidden, header: "5", expectCode: http.StatusForbidden},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.header != "" {
+					w.Header().Set("Retry-After", tc.header)
+				}
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			r, err := http.NewRequest("", "", nil)
+			require.NoError(t, err)
+			config := fmt.Sprintf(`{"remote":"%s","payload":"{}","retryable_denial":{"status_codes":[423],"retry_after_path":"retry_after"}}`, server.URL)
+			err = a.Authorize(r, new(authn.AuthenticationSession), json.RawMessage(config), &rule.Rule{})
+			require.Error(t, err)
+
+			var statusErr *herodot.DefaultError
+			require.True(t, errors.As(err, &statusErr))
+			assert.Equal(t, tc.expectCode, statusErr.StatusCode())
+
+			var withHeader *helper.ErrWithHeader
+			if tc.retryAfter == "" {
+				assert.False(t, errors.As(err, &withHeader))
+				return
+			}
+			require.True(t, errors.As(err, &withHeader))
+			assert.Equal(t, tc.retryAfter, withHeader.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestAuthorizerRemoteJSONDeduplicate(t *testing.T) {
+	t.Parallel()
+
+	const n = 10
+	var calls atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		w.Header().Set("X-Decision", "allowed")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	config := json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{\"subject\":\"{{ .Subject }}\"}","forward_response_headers_to_upstream":["X-Decision"]}`, server.URL))
+	sessions := make([]*authn.AuthenticationSession, n)
+	errs := make(chan error, n)
+	for i := range sessions {
+		sessions[i] = &authn.AuthenticationSession{Subject: "alice"}
+		go func(session *authn.AuthenticationSession) {
+			r, err := http.NewRequest("", "", nil)
+			if err != nil {
+				errs <- err
+				return
+			}
+			errs <- a.Authorize(r, session, config, &rule.Rule{})
+		}(sessions[i])
+	}
+
+	// Give all requests time to join the call in flight before the remote answers.
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+
+	for range sessions {
+		require.NoError(t, <-errs)
+	}
+	assert.EqualValues(t, 1, calls.Load())
+	for _, session := range sessions {
+		assert.Equal(t, "allowed", session.Header.Get("X-Decision"))
+	}
+}
+
+func TestAuthorizerRemoteJSONHeaderNames(t *testing.T) {
+	t.Parallel()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	for _, tc := range []struct {
+		name    string
+		headers string
+		err     string
+		expect  map[string]string
+	}{
+		{name: "canonicalized", headers: `{"x-tenant":"{{ .Subject }}"}`, expect: map[string]string{"X-Tenant": "{{ .Subject }}"}},
+		{name: "space", headers: `{"X Tenant":"a"}`, err: `header name "X Tenant" is not a valid HTTP header name`},
+		{name: "colon", headers: `{"X-Tenant:":"a"}`, err: `header name "X-Tenant:" is not a valid HTTP header name`},
+		{name: "duplicate after canonicalization", headers: `{"X-Tenant":"a","x-tenant":"b"}`, err: `header "X-Tenant" is configured more than once`},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			c, err := a.Config(json.RawMessage(fmt.Sprintf(`{"remote":"http://host/path","payload":"{}","headers":%s}`, tc.headers)))
+			if tc.err != "" {
+				var herr *herodot.DefaultError
+				require.True(t, errors.As(err, &herr))
+				assert.Contains(t, herr.Reason(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, c.Headers)
+		})
+	}
+}
+
+func TestAuthorizerRemoteJSONHeaderConditions(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Admin", r.Header.Get("X-Admin"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	config := json.RawMessage(fmt.Sprintf(`{
+		"remote": "%s",
+		"payload": "{}",
+		"headers": {"X-Admin": "true"},
+		"header_conditions": {"x-admin": "{{ has \"admin\" .Extra.roles }}"},
+		"forward_response_headers_to_upstream": ["X-Admin"]
+	}`, server.URL))
+
+	for _, tc := range []struct {
+		name   string
+		roles  []interface{}
+		expect string
+	}{
+		{name: "included for admins", roles: []interface{}{"admin", "user"}, expect: "true"},
+		{name: "omitted for others", roles: []interface{}{"user"}},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			r, err := http.NewRequest("", "", nil)
+			require.NoError(t, err)
+			session := &authn.AuthenticationSession{Subject: "alice", Extra: map[string]interface{}{"roles": tc.roles}}
+			require.NoError(t, a.Authorize(r, session, config, &rule.Rule{ID: "conditions"}))
+			assert.Equal(t, tc.expect, session.Header.Get("X-Admin"))
+		})
+	}
+
+	t.Run("case=condition for unknown header", func(t *testing.T) {
+		_, err := a.Config(json.RawMessage(`{"remote":"http://host/path","payload":"{}","header_conditions":{"X-Admin":"true"}}`))
+		require.Error(t, err)
+	})
+}
+
+func TestAuthorizerRemoteJSONTimeoutStatusCode(t *testing.T) {
+	t.Parallel()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slow.Close()
+
+	unreachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	unreachable.Close()
+
+	var brokenHits atomic.Int64
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		brokenHits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	for _, tc := range []struct {
+		name   string
+		remote string
+		status string
+		expect int
+	}{
+		{name: "timeout defaults to service unavailable", remote: slow.URL, expect: http.StatusServiceUnavailable},
+		{name: "timeout with configured status", remote: slow.URL, status: `,"timeout_status_code":504`, expect: http.StatusGatewayTimeout},
+		{name: "unreachable remote", remote: unreachable.URL, expect: http.StatusServiceUnavailable},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			r, err := http.NewRequest("", "", nil)
+			require.NoError(t, err)
+			err = a.Authorize(r, new(authn.AuthenticationSession), json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}","retry":{"max_delay":"50ms","give_up_after":"50ms"}%s}`, tc.remote, tc.status)), &rule.Rule{})
+			require.Error(t, err)
+
+			var herr *herodot.DefaultError
+			require.ErrorAs(t, err, &herr)
+			assert.Equal(t, tc.expect, herr.StatusCode())
+		})
+	}
+
+	t.Run("case=unexpected response is not classified after retries are used up", func(t *testing.T) {
+		r, err := http.NewRequest("", "", nil)
+		require.NoError(t, err)
+		err = a.Authorize(r, new(authn.AuthenticationSession), json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}","retry":{"max_delay":"100ms","give_up_after":"50ms"}}`, broken.URL)), &rule.Rule{})
+		require.Error(t, err)
+		assert.Greater(t, brokenHits.Load(), int64(1), "the remote must have been retried")
+
+		var herr *herodot.DefaultError
+		assert.False(t, errors.As(err, &herr), "%+v", err)
+	})
+}
+
+func TestAuthorizerRemoteJSONRetryOnStatus(t *testing.T) {
+	t.Parallel()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	// warmingUp answers with status until it has been called warmup times.
+	warmingUp := func(status int, warmup int64) (*httptest.Server, *atomic.Int64) {
+		calls := new(atomic.Int64)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) <= warmup {
+				w.WriteHeader(status)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(server.Close)
+		return server, calls
+	}
+
+	authorize := func(t *testing.T, remote, extra string) error {
+		r, err := http.NewRequest("", "", nil)
+		require.NoError(t, err)
+		return a.Authorize(r, new(authn.AuthenticationSession), json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}","retry":{"max_delay":"100ms","give_up_after":"10ms"}%s}`, remote, extra)), &rule.Rule{})
+	}
+
+	t.Run("case=too early is retried until the remote is warmed up", func(t *testing.T) {
+		server, calls := warmingUp(http.StatusTooEarly, 2)
+		require.NoError(t, authorize(t, server.URL, `,"retry_on_status":[425]`))
+		assert.EqualValues(t, 3, calls.Load())
+	})
+
+	t.Run("case=too early is not retried by default", func(t *testing.T) {
+		server, calls := warmingUp(http.StatusTooEarly, 2)
+		require.Error(t, authorize(t, server.URL, ``))
+		assert.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("case=server errors can be excluded from retries", func(t *testing.T) {
+		server, calls := warmingUp(http.StatusNotImplemented, 2)
+		require.Error(t, authorize(t, server.URL, `,"no_retry_on_status":[501]`))
+		assert.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("case=overlapping status codes are rejected", func(t *testing.T) {
+		server, calls := warmingUp(http.StatusTooEarly, 0)
+		err := authorize(t, server.URL, `,"retry_on_status":[425,429],"no_retry_on_status":[429]`)
+		var herr *herodot.DefaultError
+		require.ErrorAs(t, err, &herr)
+		assert.Contains(t, herr.Reason(), "429")
+		assert.EqualValues(t, 0, calls.Load())
+	})
+}
+
+func TestAuthorizerRemoteJSONRequestCompression(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := io.Reader(r.Body)
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			body = zr
+		}
+		payload, err := io.ReadAll(body)
+		require.NoError(t, err)
+		w.Header().Set("X-Encoding", r.Header.Get("Content-Encoding"))
+		w.Header().Set("X-Echo", string(payload))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	for _, tc := range []struct {
+		name        string
+		compression string
+		encoding    string
+	}{
+		{name: "uncompressed by default"},
+		{name: "none", compression: `,"request_compression":"none"`},
+		{name: "gzip", compression: `,"request_compression":"gzip"`, encoding: "gzip"},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			r, err := http.NewRequest("", "", nil)
+			require.NoError(t, err)
+			session := &authn.AuthenticationSession{Subject: "alice"}
+			config := fmt.Sprintf(`{"remote":"%s","payload":"{\"subject\":\"{{ .Subject }}\"}","forward_response_headers_to_upstream":["X-Echo","X-Encoding"]%s}`, server.URL, tc.compression)
+			require.NoError(t, a.Authorize(r, session, json.RawMessage(config), &rule.Rule{}))
+			assert.JSONEq(t, `{"subject":"alice"}`, session.Header.Get("X-Echo"))
+			assert.Equal(t, tc.encoding, session.Header.Get("X-Encoding"))
+		})
+	}
+}
+
+func TestAuthorizerRemoteJSONMethodPayloads(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		w.Header().Set("X-Echo", string(payload))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	config := json.RawMessage(fmt.Sprintf(`{
+		"remote": "%s",
+		"payload": "{\"subject\":\"{{ .Subject }}\"}",
+		"method_payloads": {"post": "{\"subject\":\"{{ .Subject }}\",\"action\":\"write\"}"},
+		"forward_response_headers_to_upstream": ["X-Echo"]
+	}`, server.URL))
+
+	for _, tc := range []struct {
+		method string
+		expect string
+	}{
+		{method: "GET", expect: `{"subject":"alice"}`},
+		{method: "POST", expect: `{"subject":"alice","action":"write"}`},
+		{method: "DELETE", expect: `{"subject":"alice"}`},
+	} {
+		t.Run("method="+tc.method, func(t *testing.T) {
+			r, err := http.NewRequest(tc.method, "", nil)
+			require.NoError(t, err)
+			session := &authn.AuthenticationSession{Subject: "alice"}
+			require.NoError(t, a.Authorize(r, session, config, &rule.Rule{}))
+			assert.JSONEq(t, tc.expect, session.Header.Get("X-Echo"))
+		})
+	}
+}
+
+func TestAuthorizerRemoteJSONTemplateDuration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	l := logrusx.New("", "")
+	p, err := configuration.NewKoanfProvider(context.Background(), nil, l)
+	require.NoError(t, err)
+	d := newRemoteJSONDependencies(l, p)
+	a := NewAuthorizerRemoteJSON(p, d)
+	defer a.Shutdown(context.Background()) //nolint:errcheck
+
+	authorize := func(t *testing.T, id string) {
+		r, err := http.NewRequest("", "", nil)
+		require.NoError(t, err)
+		config := json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{\"subject\":\"{{ .Subject }}\"}","headers":{"X-Subject":"{{ .Subject }}"}}`, server.URL))
+		require.NoError(t, a.Authorize(r, &authn.AuthenticationSession{Subject: "alice"}, config, &rule.Rule{ID: id}))
+	}
+
+	authorize(t, "template-duration-disabled")
+	assert.Equal(t, 0, testutil.CollectAndCount(d.m.TemplateDuration), "durations are not recorded by default")
+
+	p.SetForTest(t, configuration.PrometheusServeTemplateDurations, true)
+	authorize(t, "template-duration-enabled")
+	assert.Equal(t, 2, testutil.CollectAndCount(d.m.TemplateDuration))
+
+	for _, kind := range []string{"payload", "headers"} {
+		var m dto.Metric
+		require.NoError(t, d.m.TemplateDuration.WithLabelValues("template-duration-enabled", kind).(prometheus.Histogram).Write(&m))
+		assert.EqualValues(t, 1, m.GetHistogram().GetSampleCount(), kind)
+	}
+}
+
+func TestAuthorizerRemoteJSONHostHeader(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Host", r.Host)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	authorize := func(t *testing.T, host string) (*authn.AuthenticationSession, error) {
+		r, err := http.NewRequest("", "", nil)
+		require.NoError(t, err)
+		session := new(authn.AuthenticationSession)
+		config := fmt.Sprintf(`{"remote":"%s","payload":"{}","forward_response_headers_to_upstream":["X-Host"],"host_header":"%s"}`, server.URL, host)
+		return session, a.Authorize(r, session, json.RawMessage(config), &rule.Rule{})
+	}
+
+	t.Run("case=remote host by default", func(t *testing.T) {
+		session, err := authorize(t, "")
+		require.NoError(t, err)
+		assert.Equal(t, strings.TrimPrefix(server.URL, "http://"), session.Header.Get("X-Host"))
+	})
+
+	for _, host := range []string{"authz.internal", "authz.internal:8080", "[::1]:8080"} {
+		t.Run("case=overridden host "+host, func(t *testing.T) {
+			session, err := authorize(t, host)
+			require.NoError(t, err)
+			assert.Equal(t, host, session.Header.Get("X-Host"))
+		})
+	}
+
+	for _, host := range []string{"authz.internal/path", "user@authz.internal", "authz internal", "authz.internal:port", ":8080"} {
+		t.Run("case=invalid host "+host, func(t *testing.T) {
+			_, err := authorize(t, host)
+			var herr *herodot.DefaultError
+			require.ErrorAs(t, err, &herr)
+			assert.Contains(t, herr.Reason(), "host_header")
+		})
+	}
+}
+
+func TestAuthorizerRemoteJSONMethod(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Body", string(body))
+		w.Header().Set("X-Input", r.URL.Query().Get("input"))
+		w.Header().Set("X-Content-Type", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	for _, tc := range []struct {
+		name                        string
+		config                      string
+		method, body, input, ctType string
+	}{
+		{name: "post by default", method: "POST", body: `{"subject":"alice"}`, ctType: "application/json"},
+		{name: "put", config: `,"method":"PUT"`, method: "PUT", body: `{"subject":"alice"}`, ctType: "application/json"},
+		{name: "patch", config: `,"method":"PATCH"`, method: "PATCH", body: `{"subject":"alice"}`, ctType: "application/json"},
+		{name: "get drops the payload", config: `,"method":"GET"`, method: "GET"},
+		{name: "get with payload query parameter", config: `,"method":"GET","payload_query_parameter":"input"`, method: "GET", input: `{"subject":"alice"}`},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			r, err := http.NewRequest("", "", nil)
+			require.NoError(t, err)
+			session := &authn.AuthenticationSession{Subject: "alice"}
+			config := fmt.Sprintf(`{"remote":"%s?tenant=acme","payload":"{\"subject\":\"{{ .Subject }}\"}","forward_response_headers_to_upstream":["X-Method","X-Body","X-Input","X-Content-Type"]%s}`, server.URL, tc.config)
+			require.NoError(t, a.Authorize(r, session, json.RawMessage(config), &rule.Rule{}))
+			assert.Equal(t, tc.method, session.Header.Get("X-Method"))
+			assert.Equal(t, tc.body, session.Header.Get("X-Body"))
+			assert.Equal(t, tc.input, session.Header.Get("X-Input"))
+			assert.Equal(t, tc.ctType, session.Header.Get("X-Content-Type"))
+		})
+	}
+
+	t.Run("case=get can not be compressed", func(t *testing.T) {
+		r, err := http.NewRequest("", "", nil)
+		require.NoError(t, err)
+		config := fmt.Sprintf(`{"remote":"%s","payload":"{}","method":"GET","request_compression":"gzip"}`, server.URL)
+		err = a.Authorize(r, new(authn.AuthenticationSession), json.RawMessage(config), &rule.Rule{})
+		var herr *herodot.DefaultError
+		require.ErrorAs(t, err, &herr)
+		assert.Contains(t, herr.Reason(), "request_compression")
+	})
+}
+
+func TestAuthorizerRemoteJSONStatusCodes(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code, err := strconv.Atoi(r.URL.Query().Get("status"))
+		require.NoError(t, err)
+		w.WriteHeader(code)
+	}))
+	defer ts.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	for _, tc := range []struct {
+		name      string
+		status    int
+		codes     string
+		forbidden bool
+		fails     bool
+	}{
+		{name: "200 is accepted by default", status: http.StatusOK},
+		{name: "204 fails by default", status: http.StatusNoContent, fails: true},
+		{name: "configured 204 is accepted", status: http.StatusNoContent, codes: `,"accept_status_codes":[200,204]`},
+		{name: "403 is forbidden by default", status: http.StatusForbidden, forbidden: true},
+		{name: "422 fails by default", status: http.StatusUnprocessableEntity, fails: true},
+		{name: "configured accepted code", status: http.StatusAccepted, codes: `,"accept_status_codes":[202]`},
+		{name: "default accepted code no longer accepted", status: http.StatusOK, codes: `,"accept_status_codes":[202]`, fails: true},
+		{name: "configured forbidden code", status: http.StatusUnprocessableEntity, codes: `,"forbidden_status_codes":[422]`, forbidden: true},
+		{name: "default forbidden code no longer forbidden", status: http.StatusForbidden, codes: `,"forbidden_status_codes":[422]`, fails: true},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			r, err := http.NewRequest("", "", nil)
+			require.NoError(t, err)
+			err = a.Authorize(r, new(authn.AuthenticationSession), json.RawMessage(fmt.Sprintf(`{"remote":"%s?status=%d","payload":"{}"%s}`, ts.URL, tc.status, tc.codes)), &rule.Rule{})
+			switch {
+			case tc.forbidden:
+				assert.ErrorIs(t, err, helper.ErrForbidden)
+			case tc.fails:
+				require.Error(t, err)
+				assert.NotErrorIs(t, err, helper.ErrForbidden)
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("case=overlapping codes are rejected", func(t *testing.T) {
+		_, err := a.Config(json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}","accept_status_codes":[200],"forbidden_status_codes":[200]}`, ts.URL)))
+		require.Error(t, err)
+
+		var herr *herodot.DefaultError
+		require.ErrorAs(t, err, &herr)
+		assert.Contains(t, herr.Reason(), "accept_status_codes and forbidden_status_codes")
+	})
+
+	t.Run("case=codes overlapping with abstain codes are rejected", func(t *testing.T) {
+		for codes, reason := range map[string]string{
+			`"accept_status_codes":[200,404]`:                          "abstain_status_codes and accept_status_codes",
+			`"forbidden_status_codes":[403,404]`:                       "abstain_status_codes and forbidden_status_codes",
+			`"abstain_status_codes":[409],"accept_status_codes":[409]`: "abstain_status_codes and accept_status_codes",
+		} {
+			_, err := a.Config(json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}",%s}`, ts.URL, codes)))
+			require.Error(t, err, codes)
+
+			var herr *herodot.DefaultError
+			require.ErrorAs(t, err, &herr)
+			assert.Contains(t, herr.Reason(), reason, codes)
+		}
+
+		_, err := a.Config(json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}","abstain_status_codes":[],"accept_status_codes":[200,404]}`, ts.URL)))
+		assert.NoError(t, err, "404 can be accepted once it no longer abstains")
+	})
+}
+
+func TestAuthorizerRemoteJSONForwardRequestHeaders(t *testing.T) {
+	t.Parallel()
+
+	var received http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	r, err := http.NewRequest("", "", nil)
+	require.NoError(t, err)
+	r.Header.Set("X-Tenant-ID", "tenant-1")
+	r.Header.Add("X-Forwarded-For", "10.0.0.1")
+	r.Header.Add("X-Forwarded-For", "10.0.0.2")
+	r.Header.Set("X-Not-Forwarded", "secret")
+
+	require.NoError(t, a.Authorize(r, new(authn.AuthenticationSession), json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}","forward_request_headers":["x-tenant-id","X-FORWARDED-FOR","X-Request-ID"]}`, ts.URL)), &rule.Rule{}))
+	assert.Equal(t, "tenant-1", received.Get("X-Tenant-ID"))
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, received.Values("X-Forwarded-For"))
+	assert.NotContains(t, received, "X-Request-Id", "absent headers are skipped")
+	assert.NotContains(t, received, "X-Not-Forwarded")
+
+	t.Run("case=configured headers take precedence", func(t *testing.T) {
+		require.NoError(t, a.Authorize(r, new(authn.AuthenticationSession), json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}","forward_request_headers":["X-Tenant-ID"],"headers":{"X-Tenant-ID":"override"}}`, ts.URL)), &rule.Rule{}))
+		assert.Equal(t, []string{"override"}, received.Values("X-Tenant-ID"))
+	})
+
+	t.Run("case=invalid header names are rejected", func(t *testing.T) {
+		_, err := a.Config(json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}","forward_request_headers":["X Tenant"]}`, ts.URL)))
+		require.Error(t, err)
+
+		var herr *herodot.DefaultError
+		require.ErrorAs(t, err, &herr)
+		assert.Contains(t, herr.Reason(), "forward_request_headers")
+	})
+
+	t.Run("case=headers set by the authorizer are rejected", func(t *testing.T) {
+		for _, name := range []string{"authorization", "Content-Type"} {
+			_, err := a.Config(json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}","normalize_authorization":true,"forward_request_headers":["%s"]}`, ts.URL, name)))
+			require.Error(t, err, name)
+
+			var herr *herodot.DefaultError
+			require.ErrorAs(t, err, &herr)
+			assert.Contains(t, herr.Reason(), "is set by the authorizer", name)
+		}
+	})
+
+	t.Run("case=authorization is forwarded once", func(t *testing.T) {
+		r := r.Clone(r.Context())
+		r.Header.Set("Authorization", "bearer  token")
+
+		require.NoError(t, a.Authorize(r, new(authn.AuthenticationSession), json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}","normalize_authorization":true,"forward_request_headers":["X-Tenant-ID"]}`, ts.URL)), &rule.Rule{}))
+		assert.Equal(t, []string{"Bearer token"}, received.Values("Authorization"))
+		assert.Equal(t, []string{"application/json"}, received.Values("Content-Type"))
+	})
+}
+
+func TestAuthorizerRemoteJSONTemplatedRemote(t *testing.T) {
+	t.Parallel()
+
+	var path string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	host := strings.TrimPrefix(ts.URL, "http://")
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	config := json.RawMessage(`{"remote":"http://{{ .Extra.host }}/tenants/{{ .Extra.tenant }}","remote_hosts":["127.0.0.1"],"payload":"{}"}`)
+
+	t.Run("case=remote is rendered against the session", func(t *testing.T) {
+		r, err := http.NewRequest("", "", nil)
+		require.NoError(t, err)
+		session := &authn.AuthenticationSession{Extra: map[string]interface{}{"host": host, "tenant": "acme"}}
+		require.NoError(t, a.Authorize(r, session, config, &rule.Rule{}))
+		assert.Equal(t, "/tenants/acme", path)
+	})
+
+	t.Run("case=remote rendering to a relative URL fails", func(t *testing.T) {
+		r, err := http.NewRequest("", "", nil)
+		require.NoError(t, err)
+		session := &authn.AuthenticationSession{Extra: map[string]interface{}{"host": "", "tenant": "acme"}}
+		err = a.Authorize(r, session, config, &rule.Rule{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not an absolute URL")
+	})
+
+	t.Run("case=remote rendering to another host fails", func(t *testing.T) {
+		for _, tc := range []struct {
+			remote string
+			hosts  string
+			tenant string
+		}{
+			{remote: "http://{{ .Extra.tenant }}.policy.example/authorize", hosts: `["*.policy.example"]`, tenant: "evil.example#"},
+			{remote: "http://{{ .Extra.tenant }}.policy.example/authorize", hosts: `["*.policy.example"]`, tenant: "evil.example/"},
+			{remote: "http://{{ .Extra.tenant }}.policy.example/authorize", hosts: `["*.policy.example"]`, tenant: "user@evil.example?"},
+			{remote: "http://{{ .Extra.tenant }}/authorize", hosts: `["*.policy.example"]`, tenant: ".policy.example"},
+			{remote: "http://{{ .Extra.tenant }}/authorize", hosts: `["policy.example"]`, tenant: "acme.policy.example"},
+		} {
+			r, err := http.NewRequest("", "", nil)
+			require.NoError(t, err)
+			session := &authn.AuthenticationSession{Extra: map[string]interface{}{"tenant": tc.tenant}}
+			config := json.RawMessage(fmt.Sprintf(`{"remote":"%s","remote_hosts":%s,"payload":"{}"}`, tc.remote, tc.hosts))
+			err = a.Authorize(r, session, config, &rule.Rule{})
+			require.Error(t, err, tc.tenant)
+			assert.Contains(t, err.Error(), "which is not allowed", tc.tenant)
+		}
+	})
+
+	t.Run("case=remote with a static host keeps it", func(t *testing.T) {
+		r, err := http.NewRequest("", "", nil)
+		require.NoError(t, err)
+		session := &authn.AuthenticationSession{Extra: map[string]interface{}{"tenant": "@evil.example"}}
+		require.NoError(t, a.Authorize(r, session, json.RawMessage(fmt.Sprintf(`{"remote":"%s/tenants/{{ .Extra.tenant }}","payload":"{}"}`, ts.URL)), &rule.Rule{}))
+		assert.Equal(t, "/tenants/@evil.example", path)
+	})
+
+	for _, tc := range []struct {
+		name   string
+		remote string
+		reason string
+	}{
+		{name: "relative remote", remote: "{{ .Extra.host }}/path", reason: "not an absolute URL"},
+		{name: "malformed template", remote: "http://{{ .Extra.host /path", reason: "unable to parse the remote template"},
+		{name: "invalid remote", remote: "invalid-url", reason: "not an absolute URL"},
+		{name: "templated host without remote_hosts", remote: "http://{{ .Extra.tenant }}.policy.example/authorize", reason: "requires remote_hosts"},
+		{name: "templated port without remote_hosts", remote: "http://policy.example:{{ .Extra.port }}/authorize", reason: "requires remote_hosts"},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			_, err := a.Config(json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}"}`, tc.remote)))
+			require.Error(t, err)
+
+			var herr *herodot.DefaultError
+			require.ErrorAs(t, err, &herr)
+			assert.Contains(t, herr.Reason(), tc.reason)
+		})
+	}
+}
+
+func TestAuthorizerRemoteJSONCache(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		w.Header().Set("X-Decision", string(body))
+		switch string(body) {
+		case `{"subject":"alice"}`:
+			w.WriteHeader(http.StatusOK)
+		case `{"subject":"bob"}`:
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	config := json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{\"subject\":\"{{ .Subject }}\"}","forward_response_headers_to_upstream":["X-Decision"],"retry":{"give_up_after":"10ms"},"cache":{"ttl":"1m"}}`, ts.URL))
+	authorize := func(subject string) (*authn.AuthenticationSession, error) {
+		r, err := http.NewRequest("", "", nil)
+		require.NoError(t, err)
+		session := &authn.AuthenticationSession{Subject: subject}
+		return session, a.Authorize(r, session, config, &rule.Rule{})
+	}
+
+	for _, tc := range []struct {
+		subject string
+		cached  bool
+		assert  func(t *testing.T, session *authn.AuthenticationSession, err error)
+	}{
+		{subject: "alice", cached: true, assert: func(t *testing.T, session *authn.AuthenticationSession, err error) {
+			require.NoError(t, err)
+			assert.Equal(t, `{"subject":"alice"}`, session.Header.Get("X-Decision"), "forwarded headers are applied from the cached response")
+		}},
+		{subject: "bob", cached: true, assert: func(t *testing.T, _ *authn.AuthenticationSession, err error) {
+			assert.ErrorIs(t, err, helper.ErrForbidden)
+		}},
+		{subject: "mallory", cached: false, assert: func(t *testing.T, _ *authn.AuthenticationSession, err error) {
+			require.Error(t, err)
+		}},
+	} {
+		t.Run("subject="+tc.subject, func(t *testing.T) {
+			before := hits.Load()
+			session, err := authorize(tc.subject)
+			tc.assert(t, session, err)
+			called := hits.Load() - before
+			require.NotZero(t, called)
+
+			// Cache writes are applied asynchronously.
+			time.Sleep(10 * time.Millisecond)
+
+			before = hits.Load()
+			session, err = authorize(tc.subject)
+			tc.assert(t, session, err)
+			if tc.cached {
+				assert.Zero(t, hits.Load()-before, "the remote must not be called for a cached decision")
+			} else {
+				assert.NotZero(t, hits.Load()-before, "errors of the remote must not be cached")
+			}
+		})
+	}
+
+	t.Run("case=invalid ttl is rejected", func(t *testing.T) {
+		_, err := a.Config(json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}","cache":{"ttl":"0s"}}`, ts.URL)))
+		require.Error(t, err)
+
+		var herr *herodot.DefaultError
+		require.ErrorAs(t, err, &herr)
+		assert.Contains(t, herr.Reason(), "cache ttl")
+	})
+}
+
+// rawAuthorizerConfigProvider decodes authorizer configurations without applying the defaults of the schema.
+type rawAuthorizerConfigProvider struct {
+	configuration.Provider
+}
+
+func (p *rawAuthorizerConfigProvider) AuthorizerConfig(_ string, override json.RawMessage, dest interface{}) error {
+	return json.Unmarshal(override, dest)
+}
+
+func TestAuthorizerRemoteJSONConfigWithoutRetry(t *testing.T) {
+	t.Parallel()
+
+	l := logrusx.New("", "")
+	p, err := configuration.NewKoanfProvider(context.Background(), nil, l)
+	require.NoError(t, err)
+	raw := &rawAuthorizerConfigProvider{Provider: p}
+	a := NewAuthorizerRemoteJSON(raw, newRemoteJSONDependencies(l, raw))
+	defer a.Shutdown(context.Background()) //nolint:errcheck
+
+	c, err := a.Config(json.RawMessage(`{"remote":"http://host/path","payload":"{}"}`))
+	require.NoError(t, err)
+	assert.Equal(t, &AuthorizerRemoteJSONRetryConfiguration{Timeout: "100ms", MaxWait: "1s"}, c.Retry)
+
+	c, err = a.Config(json.RawMessage(`{"remote":"http://host/path","payload":"{}","retry":{"give_up_after":"2s"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, &AuthorizerRemoteJSONRetryConfiguration{Timeout: "100ms", MaxWait: "2s"}, c.Retry)
+}
+
+func TestAuthorizerRemoteJSONDeduplicateOutlivesCaller(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	received := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		received <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	config := json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{\"subject\":\"{{ .Subject }}\"}"}`, server.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	first, err := http.NewRequestWithContext(ctx, "", "", nil)
+	require.NoError(t, err)
+	firstErr := make(chan error, 1)
+	go func() {
+		firstErr <- a.Authorize(first, &authn.AuthenticationSession{Subject: "alice"}, config, &rule.Rule{})
+	}()
+	<-received
+
+	second, err := http.NewRequest("", "", nil)
+	require.NoError(t, err)
+	secondErr := make(chan error, 1)
+	go func() {
+		secondErr <- a.Authorize(second, &authn.AuthenticationSession{Subject: "alice"}, config, &rule.Rule{})
+	}()
+
+	// The first caller gives up on its own deadline while the call it started
+	// keeps running for the second caller.
+	require.Error(t, <-firstErr)
+	close(release)
+	require.NoError(t, <-secondErr)
+	assert.EqualValues(t, 1, calls.Load())
+}
+
+func TestAuthorizerRemoteJSONSharedCallDeadline(t *testing.T) {
+	t.Parallel()
+
+	canceled := make(chan struct{}, 2)
+	blocking := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		canceled <- struct{}{}
+	}))
+	defer blocking.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	t.Run("case=ends with the deadline of its last waiter", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		r, err := http.NewRequestWithContext(ctx, "", "", nil)
+		require.NoError(t, err)
+		config := json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{\"subject\":\"deadline\"}"}`, blocking.URL))
+		require.Error(t, a.Authorize(r, new(authn.AuthenticationSession), config, &rule.Rule{}))
+
+		select {
+		case <-canceled:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the shared call outlived all of its waiters")
+		}
+	})
+
+	t.Run("case=is capped by the timeout", func(t *testing.T) {
+		r, err := http.NewRequest("", "", nil)
+		require.NoError(t, err)
+		config := json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{\"subject\":\"timeout\"}","timeout":"50ms"}`, blocking.URL))
+		err = a.Authorize(r, new(authn.AuthenticationSession), config, &rule.Rule{})
+		var herr *herodot.DefaultError
+		require.ErrorAs(t, err, &herr)
+		assert.Equal(t, http.StatusServiceUnavailable, herr.StatusCode())
+
+		select {
+		case <-canceled:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the shared call was not bounded by the timeout")
+		}
+	})
+
+	t.Run("case=is not shared across retry settings", func(t *testing.T) {
+		var calls atomic.Int32
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			<-release
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		errs := make(chan error, 2)
+		for _, retry := range []string{`{"max_delay":"5ms"}`, `{"max_delay":"10ms"}`} {
+			config := json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}","retry":%s}`, server.URL, retry))
+			go func() {
+				r, err := http.NewRequest("", "", nil)
+				if err != nil {
+					errs <- err
+					return
+				}
+				errs <- a.Authorize(r, new(authn.AuthenticationSession), config, &rule.Rule{})
+			}()
+		}
+
+		require.Eventually(t, func() bool { return calls.Load() == 2 }, time.Second, 10*time.Millisecond)
+		close(release)
+		require.NoError(t, <-errs)
+		require.NoError(t, <-errs)
+	})
+}
+
+func TestAuthorizerRemoteJSONNormalizeAuthorization(t *testing.T) {
+	t.Parallel()
+
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	a, _ := newTestAuthorizerRemoteJSON(t)
+
+	for _, tc := range []struct {
+		name          string
+		authorization string
+		normalize     bool
+		expect        string
+	}{
+		{name: "verbatim by default", authorization: "bearer  token", expect: "bearer  token"},
+		{name: "lower-case scheme", authorization: "bearer token", normalize: true, expect: "Bearer token"},
+		{name: "extra whitespace between scheme and credentials", authorization: " BEARER   token", normalize: true, expect: "Bearer token"},
+		{name: "unknown scheme", authorization: " custom token", normalize: true, expect: "custom token"},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			config := json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}","normalize_authorization":%t}`, server.URL, tc.normalize))
+			r, err := http.NewRequest("", "", nil)
+			require.NoError(t, err)
+			r.Header.Set("Authorization", tc.authorization)
+			require.NoError(t, a.Authorize(r, new(authn.AuthenticationSession), config, &rule.Rule{}))
+			assert.Equal(t, tc.expect, received)
+		})
+	}
+}
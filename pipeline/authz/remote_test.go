@@ -9,8 +9,11 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -282,3 +285,166 @@ func TestAuthorizerRemoteValidate(t *testing.T) {
 		})
 	}
 }
+
+// repeatReader endlessly yields the same byte without allocating.
+type repeatReader byte
+
+func (b repeatReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(b)
+	}
+	return len(p), nil
+}
+
+func TestAuthorizerRemoteBodySpool(t *testing.T) {
+	const size = 32 << 20
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	l := logrusx.New("", "")
+	p, err := configuration.NewKoanfProvider(context.Background(), nil, l)
+	require.NoError(t, err)
+	a := NewAuthorizerRemote(p, otelx.NewNoop(l, p.TracingConfig()))
+
+	allocated := func(t *testing.T, threshold int) uint64 {
+		config, err := sjson.SetBytes([]byte(`{}`), "remote", server.URL)
+		require.NoError(t, err)
+		config, err = sjson.SetBytes(config, "body_spool_threshold", threshold)
+		require.NoError(t, err)
+
+		r := &http.Request{
+			Header: http.Header{"Content-Type": {"application/octet-stream"}},
+			Body:   io.NopCloser(io.LimitReader(repeatReader('a'), size)),
+		}
+
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		require.NoError(t, a.Authorize(r, new(authn.AuthenticationSession), config, &rule.Rule{}))
+		runtime.ReadMemStats(&after)
+
+		n, err := io.Copy(io.Discard, r.Body)
+		require.NoError(t, err)
+		assert.EqualValues(t, size, n, "body must stay intact")
+		require.NoError(t, r.Body.Close())
+		return after.TotalAlloc - before.TotalAlloc
+	}
+
+	buffered := allocated(t, 0)
+	streamed := allocated(t, 1<<20)
+	assert.Greater(t, buffered, uint64(size), "the buffered copy holds the whole body in memory")
+	assert.Less(t, streamed*4, buffered, "the spooled copy must use a fraction of the memory, buffered: %d, streamed: %d", buffered, streamed)
+}
+
+func TestAuthorizerRemoteBodySpoolCleanup(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	l := logrusx.New("", "")
+	p, err := configuration.NewKoanfProvider(context.Background(), nil, l)
+	require.NoError(t, err)
+	a := NewAuthorizerRemote(p, otelx.NewNoop(l, p.TracingConfig()))
+
+	config, err := sjson.SetBytes([]byte(`{"body_spool_threshold":1024}`), "remote", server.URL)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := (&http.Request{
+		Header: http.Header{"Content-Type": {"application/octet-stream"}},
+		Body:   io.NopCloser(io.LimitReader(repeatReader('a'), 1<<20)),
+	}).WithContext(ctx)
+	require.Error(t, a.Authorize(r, new(authn.AuthenticationSession), config, &rule.Rule{}))
+
+	spooled := func() []string {
+		files, err := filepath.Glob(filepath.Join(dir, "oathkeeper-body-*"))
+		require.NoError(t, err)
+		return files
+	}
+	require.Eventually(t, func() bool { return len(spooled()) == 1 }, time.Second, 10*time.Millisecond)
+
+	// The denied request is never forwarded, so its body is not closed by the upstream.
+	cancel()
+	assert.Eventually(t, func() bool { return len(spooled()) == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestAuthorizerRemoteNormalizeAuthorization(t *testing.T) {
+	t.Parallel()
+
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	l := logrusx.New("", "")
+	p, err := configuration.NewKoanfProvider(context.Background(), nil, l)
+	require.NoError(t, err)
+	a := NewAuthorizerRemote(p, otelx.NewNoop(l, p.TracingConfig()))
+
+	for _, tc := range []struct {
+		name          string
+		authorization string
+		normalize     bool
+		expect        string
+	}{
+		{name: "verbatim by default", authorization: "bearer  token", expect: "bearer  token"},
+		{name: "lower-case scheme", authorization: "bearer token", normalize: true, expect: "Bearer token"},
+		{name: "leading whitespace", authorization: "  Bearer token", normalize: true, expect: "Bearer token"},
+		{name: "extra whitespace between scheme and credentials", authorization: "BEARER   token", normalize: true, expect: "Bearer token"},
+		{name: "unknown scheme", authorization: " custom token", normalize: true, expect: "custom token"},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			config, err := sjson.SetBytes([]byte(`{}`), "remote", server.URL)
+			require.NoError(t, err)
+			config, err = sjson.SetBytes(config, "normalize_authorization", tc.normalize)
+			require.NoError(t, err)
+
+			r := &http.Request{Header: http.Header{"Authorization": {tc.authorization}}}
+			require.NoError(t, a.Authorize(r, new(authn.AuthenticationSession), config, &rule.Rule{}))
+			assert.Equal(t, tc.expect, received)
+		})
+	}
+}
+
+func TestAuthorizerRemoteForwardsContentType(t *testing.T) {
+	t.Parallel()
+
+	const body = `<?xml version="1.0"?><order id="1"/>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/xml; charset=utf-8", r.Header.Get("Content-Type"))
+		received, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, body, string(received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	l := logrusx.New("", "")
+	p, err := configuration.NewKoanfProvider(context.Background(), nil, l)
+	require.NoError(t, err)
+	a := NewAuthorizerRemote(p, otelx.NewNoop(l, p.TracingConfig()))
+
+	config, err := sjson.SetBytes([]byte(`{}`), "remote", server.URL)
+	require.NoError(t, err)
+	r := &http.Request{
+		Header: http.Header{"Content-Type": {"application/xml; charset=utf-8"}},
+		Body:   io.NopCloser(strings.NewReader(body)),
+	}
+	require.NoError(t, a.Authorize(r, new(authn.AuthenticationSession), config, &rule.Rule{}))
+
+	forwarded, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(forwarded), "body must stay intact")
+}
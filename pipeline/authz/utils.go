@@ -5,21 +5,315 @@ package authz
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template/parse"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/oathkeeper/pipeline"
+	"github.com/ory/oathkeeper/pipeline/authn"
+	"github.com/ory/oathkeeper/x"
 )
 
-func pipeRequestBody(r *http.Request, w io.Writer) error {
+var sessionType = reflect.TypeOf(authn.AuthenticationSession{})
+
+// streamRequestBody returns a reader which streams the body of r while pipeRequestBody replaces the body of
+// r with a copy, so that the body can be sent to a remote without reading it into memory first.
+func streamRequestBody(r *http.Request, spoolThreshold int64, rl pipeline.Rule) io.ReadCloser {
+	read, write := io.Pipe()
+	go func() {
+		err := pipeRequestBody(r, write, spoolThreshold)
+		write.CloseWithError(errors.Wrapf(err, `could not pipe request body in rule "%s"`, rl.GetID()))
+	}()
+	return read
+}
+
+// pipeRequestBody copies the body of r to w and replaces the body of r with a copy so that it can be read
+// again. Once the copy exceeds spoolThreshold bytes it is spooled to a temporary file, which is removed when
+// the body is closed or the context of r is done, whichever happens first. A spoolThreshold of 0 keeps the
+// whole copy in memory.
+func pipeRequestBody(r *http.Request, w io.Writer, spoolThreshold int64) error {
 	if r.Body == nil {
 		return nil
 	}
 
-	var body bytes.Buffer
+	s := &spool{threshold: spoolThreshold}
 	defer r.Body.Close() //nolint:errcheck
-	_, err := io.Copy(w, io.TeeReader(r.Body, &body))
+	if _, err := io.Copy(w, io.TeeReader(r.Body, s)); err != nil {
+		s.discard()
+		return err
+	}
+
+	body, err := s.body()
 	if err != nil {
+		s.discard()
+		return errors.WithStack(err)
+	}
+	if spooled, ok := body.(*spooledBody); ok {
+		// The upstream might never read or close the body, for example if the request is denied.
+		context.AfterFunc(r.Context(), func() { _ = spooled.Close() })
+	}
+	r.Body = body
+	return nil
+}
+
+// spool keeps what is written to it in memory until it exceeds threshold bytes and in a temporary file
+// afterwards.
+type spool struct {
+	threshold int64
+	buf       bytes.Buffer
+	file      *os.File
+}
+
+func (s *spool) Write(p []byte) (int, error) {
+	if s.file == nil && (s.threshold <= 0 || int64(s.buf.Len()+len(p)) <= s.threshold) {
+		return s.buf.Write(p)
+	}
+
+	if s.file == nil {
+		f, err := os.CreateTemp("", "oathkeeper-body-*")
+		if err != nil {
+			return 0, err
+		}
+		s.file = f
+		if _, err := s.buf.WriteTo(f); err != nil {
+			return 0, err
+		}
+	}
+	return s.file.Write(p)
+}
+
+// body returns a reader over everything written to the spool.
+func (s *spool) body() (io.ReadCloser, error) {
+	if s.file == nil {
+		return io.NopCloser(&s.buf), nil
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return &spooledBody{File: s.file}, nil
+}
+
+// discard removes the temporary file of the spool, if any.
+func (s *spool) discard() {
+	if s.file != nil {
+		_ = (&spooledBody{File: s.file}).Close()
+	}
+}
+
+// spooledBody is a request body read from a temporary file which is removed when the body is closed. It may
+// be closed more than once.
+type spooledBody struct {
+	*os.File
+	once sync.Once
+	err  error
+}
+
+func (b *spooledBody) Close() error {
+	b.once.Do(func() {
+		b.err = b.File.Close()
+		_ = os.Remove(b.Name())
+	})
+	return b.err
+}
+
+// validHeaderName reports whether name is a valid HTTP header field name, i.e. a non-empty token as
+// defined by RFC 9110.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range []byte(name) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// truthy reports whether the rendered output of a condition template counts as true. Empty output and
+// values strconv.ParseBool considers false, such as "false" or "0", are false; everything else is true.
+func truthy(rendered string) bool {
+	rendered = strings.TrimSpace(rendered)
+	if rendered == "" {
+		return false
+	}
+	if b, err := strconv.ParseBool(rendered); err == nil {
+		return b
+	}
+	return true
+}
+
+// validateSessionTemplate parses the template and checks that every field it references on the
+// authentication session exists.
+func validateSessionTemplate(text, left, right string) error {
+	return validateTemplate(text, left, right, sessionType)
+}
+
+// validateTemplate parses the template and checks that every field it references on data of type root
+// exists. Fields below maps or interfaces cannot be checked statically and are accepted. Fields referenced
+// inside range and with blocks are skipped because dot changes there. Empty delimiters select the
+// text/template defaults.
+func validateTemplate(text, left, right string, root reflect.Type) error {
+	t, err := x.NewTemplate("validate").Delims(left, right).Parse(text)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if t.Tree == nil {
+		return nil
+	}
+	return validateTemplateNode(t.Tree.Root, root, root)
+}
+
+func validateTemplateNode(node parse.Node, dot, root reflect.Type) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := validateTemplateNode(child, dot, root); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return validateTemplateNode(n.Pipe, dot, root)
+	case *parse.IfNode:
+		return validateTemplateBranch(&n.BranchNode, dot, dot, root)
+	case *parse.RangeNode:
+		return validateTemplateBranch(&n.BranchNode, nil, dot, root)
+	case *parse.WithNode:
+		return validateTemplateBranch(&n.BranchNode, nil, dot, root)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			if err := validateTemplateNode(cmd, dot, root); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if err := validateTemplateNode(arg, dot, root); err != nil {
+				return err
+			}
+		}
+	case *parse.ChainNode:
+		return validateTemplateNode(n.Node, dot, root)
+	case *parse.FieldNode:
+		return validateTemplateFields(dot, n.Ident)
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			return validateTemplateFields(root, n.Ident[1:])
+		}
+	}
+	return nil
+}
+
+func validateTemplateBranch(n *parse.BranchNode, inner, outer, root reflect.Type) error {
+	if err := validateTemplateNode(n.Pipe, outer, root); err != nil {
 		return err
 	}
-	r.Body = io.NopCloser(&body)
-	return err
+	if inner != nil {
+		if err := validateTemplateNode(n.List, inner, root); err != nil {
+			return err
+		}
+	}
+	return validateTemplateNode(n.ElseList, outer, root)
+}
+
+func validateTemplateFields(typ reflect.Type, fields []string) error {
+	for _, field := range fields {
+		if typ == nil {
+			return nil
+		}
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		if _, ok := reflect.PointerTo(typ).MethodByName(field); ok {
+			return nil
+		}
+
+		switch typ.Kind() {
+		case reflect.Map, reflect.Interface:
+			return nil
+		case reflect.Struct:
+			f, ok := typ.FieldByName(field)
+			if !ok || !f.IsExported() {
+				return errors.Errorf(`field "%s" does not exist on %s`, field, typ)
+			}
+			typ = f.Type
+		default:
+			return errors.Errorf(`field "%s" can not be accessed on %s`, field, typ)
+		}
+	}
+	return nil
+}
+
+// authorizationSchemes maps lower-case authentication schemes to their registered spelling.
+var authorizationSchemes = map[string]string{
+	"basic":     "Basic",
+	"bearer":    "Bearer",
+	"digest":    "Digest",
+	"dpop":      "DPoP",
+	"negotiate": "Negotiate",
+}
+
+// normalizeAuthorization trims the value of an Authorization header, separates the scheme from the
+// credentials with a single space and spells well-known schemes as registered, e.g. "bearer" as "Bearer".
+func normalizeAuthorization(value string) string {
+	scheme, credentials, _ := strings.Cut(strings.TrimSpace(value), " ")
+	if canonical, ok := authorizationSchemes[strings.ToLower(scheme)]; ok {
+		scheme = canonical
+	}
+	if credentials = strings.TrimSpace(credentials); credentials == "" {
+		return scheme
+	}
+	return scheme + " " + credentials
+}
+
+// validHost reports whether host is a host name or IP address, optionally followed by a port, as used in
+// the Host header.
+func validHost(host string) bool {
+	u, err := url.Parse("http://" + host)
+	return err == nil && u.Host == host && u.Hostname() != "" && u.User == nil
+}
+
+// absoluteURL reports whether raw parses as an absolute URL with a host.
+func absoluteURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.IsAbs() && u.Host != ""
+}
+
+// withoutActions replaces the template actions of text, delimited by left and right, with a placeholder so
+// that the static parts of a template can be validated before it is rendered.
+func withoutActions(text, left, right string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(text, left)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(text[start+len(left):], right)
+		if end < 0 {
+			break
+		}
+		b.WriteString(text[:start])
+		b.WriteString("placeholder")
+		text = text[start+len(left)+end+len(right):]
+	}
+	b.WriteString(text)
+	return b.String()
 }
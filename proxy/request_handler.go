@@ -148,6 +148,13 @@ func (d *requestHandler) HandleError(w http.ResponseWriter, r *http.Request, rl
 		return
 	}
 
+	var withHeader *helper.ErrWithHeader
+	if errors.As(handleErr, &withHeader) {
+		for k, v := range withHeader.Header() {
+			w.Header()[k] = v
+		}
+	}
+
 	if err := h.Handle(w, r, config, rl, handleErr); err != nil {
 		d.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrInternalServerError.WithReasonf(
 			`Unable to execute error handler "%s". This is either a bug or a configuration issue and should be reported to the administrator. Returned error: "%s". Original error: "%s"`, h.GetID(), err, handleErr,
@@ -263,7 +270,23 @@ func (d *requestHandler) HandleRequest(r *http.Request, rl *rule.Rule) (session
 		return nil, err
 	}
 
-	if err := azh.Authorize(r, session, rl.Authorizer.Config, rl); err != nil {
+	if err := azh.Authorize(r, session, rl.Authorizer.Config, rl); errors.Is(err, authz.ErrAuthorizerNotResponsible) {
+		if d.c.AuthorizerDefaultDecision() != "allow" {
+			err := errors.WithStack(helper.ErrForbidden)
+			d.r.Logger().WithError(err).
+				WithFields(fields).
+				WithField("granted", false).
+				WithField("authorization_handler", rl.Authorizer.Handler).
+				WithField("reason_id", "authorization_handler_no_decision").
+				Warn("The authorization handler abstained and requests are denied by default")
+			return nil, err
+		}
+		d.r.Logger().
+			WithFields(fields).
+			WithField("authorization_handler", rl.Authorizer.Handler).
+			WithField("reason_id", "authorization_handler_no_decision").
+			Info("The authorization handler abstained and requests are allowed by default")
+	} else if err != nil {
 		d.r.Logger().
 			WithError(err).
 			WithFields(fields).
@@ -17,6 +17,7 @@ import (
 	"github.com/ory/x/logrusx"
 
 	"github.com/ory/oathkeeper/driver/configuration"
+	"github.com/ory/oathkeeper/helper"
 	"github.com/ory/oathkeeper/internal"
 	"github.com/ory/oathkeeper/pipeline/authn"
 	"github.com/ory/oathkeeper/x"
@@ -50,6 +51,14 @@ func TestHandleError(t *testing.T) {
 				assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
 			},
 		},
+		{
+			d:        "should send headers carried by the error",
+			inputErr: helper.WithHeader(&herodot.ErrForbidden, http.Header{"Www-Authenticate": {`Bearer error="insufficient_scope"`}}),
+			assert: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, 403, w.Code)
+				assert.Equal(t, `Bearer error="insufficient_scope"`, w.Header().Get("WWW-Authenticate"))
+			},
+		},
 		{
 			d:        "should return a 500 error when no handler is enabled",
 			inputErr: &herodot.ErrNotFound,
@@ -443,6 +452,45 @@ func TestRequestHandler(t *testing.T) {
 	}
 }
 
+func TestRequestHandlerDefaultDecision(t *testing.T) {
+	abstaining := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer abstaining.Close()
+
+	for k, tc := range []struct {
+		d         string
+		decision  string
+		expectErr error
+	}{
+		{d: "abstaining authorizer denies by default", expectErr: helper.ErrForbidden},
+		{d: "abstaining authorizer denies explicitly", decision: "deny", expectErr: helper.ErrForbidden},
+		{d: "abstaining authorizer allows", decision: "allow"},
+	} {
+		t.Run(fmt.Sprintf("case=%d/description=%s", k, tc.d), func(t *testing.T) {
+			conf := internal.NewConfigurationWithDefaults()
+			reg := internal.NewRegistry(conf)
+			conf.SetForTest(t, configuration.AuthenticatorNoopIsEnabled, true)
+			conf.SetForTest(t, configuration.AuthorizerRemoteJSONIsEnabled, true)
+			conf.SetForTest(t, configuration.MutatorNoopIsEnabled, true)
+			if tc.decision != "" {
+				conf.SetForTest(t, configuration.AuthorizerDefaultDecision, tc.decision)
+			}
+
+			_, err := reg.ProxyRequestHandler().HandleRequest(newTestRequest("http://localhost"), &rule.Rule{
+				Authenticators: []rule.Handler{{Handler: "noop"}},
+				Authorizer:     rule.Handler{Handler: "remote_json", Config: json.RawMessage(fmt.Sprintf(`{"remote":"%s","payload":"{}"}`, abstaining.URL))},
+				Mutators:       []rule.Handler{{Handler: "noop"}},
+			})
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestInitializeSession(t *testing.T) {
 	for k, tc := range []struct {
 		d                string
@@ -4,18 +4,67 @@
 package rule
 
 import (
-	"errors"
 	"hash/crc64"
+	"slices"
+	"strconv"
 
 	"github.com/dlclark/regexp2"
+	"github.com/pkg/errors"
 
 	"github.com/ory/ladon/compiler"
+
+	"github.com/ory/oathkeeper/driver/configuration"
 )
 
+// RegexpEvaluation is the outcome of evaluating a regexp rule pattern against an input.
+type RegexpEvaluation struct {
+	Matches         bool              `json:"matches"`
+	Submatches      []string          `json:"submatches"`
+	NamedSubmatches map[string]string `json:"named_submatches"`
+}
+
+// EvaluateRegexp evaluates the pattern against the input using the same matching engine
+// which is used for rules with the regexp matching strategy. maxCaptureGroups and autoAnchor
+// correspond to access_rules.max_capture_groups and access_rules.auto_anchor.
+func EvaluateRegexp(pattern, input string, maxCaptureGroups int, autoAnchor bool) (*RegexpEvaluation, error) {
+	re := &regexpMatchingEngine{maxCaptureGroups: maxCaptureGroups, autoAnchor: autoAnchor}
+	matches, err := re.IsMatching(pattern, input)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RegexpEvaluation{Matches: matches, Submatches: []string{}, NamedSubmatches: map[string]string{}}
+	if !matches {
+		return result, nil
+	}
+
+	if result.Submatches, err = re.FindStringSubmatch(pattern, input); err != nil {
+		return nil, err
+	}
+	if result.NamedSubmatches, err = re.FindNamedStringSubmatch(pattern, input); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DefaultMaxCaptureGroups is the maximum number of capture groups a regexp pattern may declare
+// if no other limit is configured.
+const DefaultMaxCaptureGroups = configuration.DefaultAccessRuleMaxCaptureGroups
+
 type regexpMatchingEngine struct {
-	compiled *regexp2.Regexp
-	checksum uint64
-	table    *crc64.Table
+	compiled         *regexp2.Regexp
+	checksum         uint64
+	table            *crc64.Table
+	maxCaptureGroups int
+
+	// autoAnchor anchors compiled patterns to the very start and end of the input with \A and \z. The
+	// ladon compiler already wraps patterns in ^ and $, but $ also matches before a trailing newline, so
+	// that a pattern for /admin also matches "/admin\n" unless this option is enabled.
+	autoAnchor bool
+
+	// anyPatterns and anyCompiled hold the patterns last compiled by IsMatchingAny.
+	anyPatterns []string
+	anyCompiled []*regexp2.Regexp
 }
 
 func (re *regexpMatchingEngine) compile(pattern string) error {
@@ -23,7 +72,7 @@ func (re *regexpMatchingEngine) compile(pattern string) error {
 		re.table = crc64.MakeTable(polynomial)
 	}
 	if checksum := crc64.Checksum([]byte(pattern), re.table); checksum != re.checksum {
-		compiled, err := compiler.CompileRegex(pattern, '<', '>')
+		compiled, err := re.compilePattern(pattern)
 		if err != nil {
 			return err
 		}
@@ -33,6 +82,47 @@ func (re *regexpMatchingEngine) compile(pattern string) error {
 	return nil
 }
 
+// compileAll compiles patterns, reusing the result of the previous call if the patterns did not change.
+func (re *regexpMatchingEngine) compileAll(patterns []string) ([]*regexp2.Regexp, error) {
+	if re.anyCompiled != nil && slices.Equal(patterns, re.anyPatterns) {
+		return re.anyCompiled, nil
+	}
+
+	compiled := make([]*regexp2.Regexp, len(patterns))
+	for k, pattern := range patterns {
+		var err error
+		if compiled[k], err = re.compilePattern(pattern); err != nil {
+			return nil, err
+		}
+	}
+	re.anyPatterns = slices.Clone(patterns)
+	re.anyCompiled = compiled
+	return compiled, nil
+}
+
+// compilePattern compiles pattern, anchoring it if autoAnchor is set and enforcing maxCaptureGroups.
+func (re *regexpMatchingEngine) compilePattern(pattern string) (*regexp2.Regexp, error) {
+	compiled, err := compiler.CompileRegex(pattern, '<', '>')
+	if err != nil {
+		return nil, err
+	}
+	if re.autoAnchor {
+		// The ladon compiler uses the RE2 option as well.
+		if compiled, err = regexp2.Compile(`\A(?:`+compiled.String()+`)\z`, regexp2.RE2); err != nil {
+			return nil, err
+		}
+	}
+	maxCaptureGroups := re.maxCaptureGroups
+	if maxCaptureGroups <= 0 {
+		maxCaptureGroups = DefaultMaxCaptureGroups
+	}
+	// The first group number is the whole match.
+	if groups := len(compiled.GetGroupNumbers()) - 1; groups > maxCaptureGroups {
+		return nil, errors.Wrapf(ErrTooManyCaptureGroups, "pattern declares %d capture groups but at most %d are allowed", groups, maxCaptureGroups)
+	}
+	return compiled, nil
+}
+
 // Checksum of a saved pattern.
 func (re *regexpMatchingEngine) Checksum() uint64 {
 	return re.checksum
@@ -46,6 +136,27 @@ func (re *regexpMatchingEngine) IsMatching(pattern, matchAgainst string) (bool,
 	return re.compiled.MatchString(matchAgainst)
 }
 
+// IsMatchingAny determines whether the input matches any of the patterns. Patterns are tried in order
+// and the index of the first matching pattern is returned, or -1 if none matches. The patterns are only
+// compiled again if they differ from the previous call.
+func (re *regexpMatchingEngine) IsMatchingAny(patterns []string, matchAgainst string) (bool, int, error) {
+	compiled, err := re.compileAll(patterns)
+	if err != nil {
+		return false, -1, err
+	}
+
+	for k, pattern := range compiled {
+		matches, err := pattern.MatchString(matchAgainst)
+		if err != nil {
+			return false, -1, err
+		}
+		if matches {
+			return true, k, nil
+		}
+	}
+	return false, -1, nil
+}
+
 // ReplaceAllString replaces all matches in `input` with `replacement`.
 func (re *regexpMatchingEngine) ReplaceAllString(pattern, input, replacement string) (string, error) {
 	if err := re.compile(pattern); err != nil {
@@ -72,3 +183,62 @@ func (re *regexpMatchingEngine) FindStringSubmatch(pattern, matchAgainst string)
 
 	return result, nil
 }
+
+// GroupNames returns the names of all named capture groups declared by the pattern.
+// Unnamed groups are omitted.
+func (re *regexpMatchingEngine) GroupNames(pattern string) ([]string, error) {
+	if err := re.compile(pattern); err != nil {
+		return nil, err
+	}
+
+	return re.namedGroups(), nil
+}
+
+// FindNamedStringSubmatch returns the captures of all named groups in matchAgainst following the pattern.
+// Named groups which did not participate in the match are returned as empty strings.
+func (re *regexpMatchingEngine) FindNamedStringSubmatch(pattern, matchAgainst string) (map[string]string, error) {
+	result, _, err := re.FindNamedStringSubmatchWithDefaults(pattern, matchAgainst, nil)
+	return result, err
+}
+
+// FindNamedStringSubmatchWithDefaults returns the captures of all named groups in matchAgainst following
+// the pattern. Named groups which did not participate in the match are set to their value in defaults, or
+// to an empty string if no default exists. The second return value reports for each named group whether
+// it participated in the match.
+func (re *regexpMatchingEngine) FindNamedStringSubmatchWithDefaults(pattern, matchAgainst string, defaults map[string]string) (map[string]string, map[string]bool, error) {
+	if err := re.compile(pattern); err != nil {
+		return nil, nil, err
+	}
+
+	m, _ := re.compiled.FindStringMatch(matchAgainst)
+	if m == nil {
+		return nil, nil, errors.New("not match")
+	}
+
+	result := map[string]string{}
+	present := map[string]bool{}
+	for _, name := range re.namedGroups() {
+		group := m.GroupByName(name)
+		if group == nil || len(group.Captures) == 0 {
+			result[name] = defaults[name]
+			present[name] = false
+			continue
+		}
+		result[name] = group.String()
+		present[name] = true
+	}
+
+	return result, present, nil
+}
+
+// namedGroups returns the names of the named groups of the compiled pattern.
+func (re *regexpMatchingEngine) namedGroups() []string {
+	result := []string{}
+	for _, name := range re.compiled.GetGroupNames() {
+		if _, err := strconv.Atoi(name); err == nil {
+			continue
+		}
+		result = append(result, name)
+	}
+	return result
+}
@@ -4,9 +4,12 @@
 package rule
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFindStringSubmatch(t *testing.T) {
@@ -70,3 +73,288 @@ func TestFindStringSubmatch(t *testing.T) {
 		})
 	}
 }
+
+func TestGroupNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "bad pattern",
+			pattern: `urn:foo:<.?>`,
+			wantErr: true,
+		},
+		{
+			name:    "no named groups",
+			pattern: `urn:foo:<.*>:<.*>`,
+			want:    []string{},
+		},
+		{
+			name:    "several groups, some named",
+			pattern: `urn:foo:<(?P<user>[^:]+)>:<.*>:<(?P<action>[^:]+)>`,
+			want:    []string{"user", "action"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regexpEngine := new(regexpMatchingEngine)
+			got, err := regexpEngine.GroupNames(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GroupNames() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			assert.ElementsMatch(t, got, tt.want, "GroupNames() got = %v, want %v", got, tt.want)
+		})
+	}
+}
+
+func TestFindNamedStringSubmatchWithDefaults(t *testing.T) {
+	const pattern = `/files/<(?P<name>[a-z]*)><(?:\.(?P<ext>[a-z]+))?>`
+
+	tests := []struct {
+		name         string
+		pattern      string
+		matchAgainst string
+		defaults     map[string]string
+		want         map[string]string
+		wantPresent  map[string]bool
+		wantErr      bool
+	}{
+		{
+			name:         "bad pattern",
+			pattern:      `urn:foo:<.?>`,
+			matchAgainst: "urn:foo:user",
+			wantErr:      true,
+		},
+		{
+			name:         "no match",
+			pattern:      pattern,
+			matchAgainst: "/users/foo",
+			wantErr:      true,
+		},
+		{
+			name:         "all optional groups participate",
+			pattern:      pattern,
+			matchAgainst: "/files/report.pdf",
+			defaults:     map[string]string{"ext": "html"},
+			want:         map[string]string{"name": "report", "ext": "pdf"},
+			wantPresent:  map[string]bool{"name": true, "ext": true},
+		},
+		{
+			name:         "absent optional group is defaulted",
+			pattern:      pattern,
+			matchAgainst: "/files/report",
+			defaults:     map[string]string{"ext": "html"},
+			want:         map[string]string{"name": "report", "ext": "html"},
+			wantPresent:  map[string]bool{"name": true, "ext": false},
+		},
+		{
+			name:         "absent optional group without default",
+			pattern:      pattern,
+			matchAgainst: "/files/report",
+			want:         map[string]string{"name": "report", "ext": ""},
+			wantPresent:  map[string]bool{"name": true, "ext": false},
+		},
+		{
+			name:         "matched empty is distinguished from absent",
+			pattern:      pattern,
+			matchAgainst: "/files/",
+			defaults:     map[string]string{"name": "index", "ext": "html"},
+			want:         map[string]string{"name": "", "ext": "html"},
+			wantPresent:  map[string]bool{"name": true, "ext": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regexpEngine := new(regexpMatchingEngine)
+			got, present, err := regexpEngine.FindNamedStringSubmatchWithDefaults(tt.pattern, tt.matchAgainst, tt.defaults)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FindNamedStringSubmatchWithDefaults() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantPresent, present)
+		})
+	}
+}
+
+func TestEvaluateRegexp(t *testing.T) {
+	t.Run("case=bad pattern", func(t *testing.T) {
+		_, err := EvaluateRegexp(`urn:foo:<.?>`, "urn:foo:user", 0, false)
+		assert.Error(t, err)
+	})
+
+	t.Run("case=no match", func(t *testing.T) {
+		got, err := EvaluateRegexp(`urn:foo:<.*>`, "urn:bar:user", 0, false)
+		require.NoError(t, err)
+		assert.Equal(t, &RegexpEvaluation{Matches: false, Submatches: []string{}, NamedSubmatches: map[string]string{}}, got)
+	})
+
+	t.Run("case=match", func(t *testing.T) {
+		got, err := EvaluateRegexp(`urn:foo:<(?P<user>[^:]+)>:<.*>`, "urn:foo:user:one", 0, false)
+		require.NoError(t, err)
+		assert.True(t, got.Matches)
+		assert.ElementsMatch(t, []string{"user", "one", "user"}, got.Submatches)
+		assert.Equal(t, map[string]string{"user": "user"}, got.NamedSubmatches)
+	})
+
+	t.Run("case=max capture groups", func(t *testing.T) {
+		_, err := EvaluateRegexp(`urn:foo:<.*>:<.*>`, "urn:foo:user:one", 1, false)
+		assert.ErrorIs(t, err, ErrTooManyCaptureGroups)
+	})
+
+	t.Run("case=auto anchor", func(t *testing.T) {
+		got, err := EvaluateRegexp(`urn:foo:<.*>`, "urn:foo:user\n", 0, false)
+		require.NoError(t, err)
+		assert.True(t, got.Matches)
+
+		got, err = EvaluateRegexp(`urn:foo:<.*>`, "urn:foo:user\n", 0, true)
+		require.NoError(t, err)
+		assert.False(t, got.Matches)
+	})
+}
+
+func TestMaxCaptureGroups(t *testing.T) {
+	manyGroups := func(n int) string {
+		return "urn:foo" + strings.Repeat(":<[a-z]*>", n)
+	}
+
+	t.Run("case=default limit is exceeded", func(t *testing.T) {
+		regexpEngine := new(regexpMatchingEngine)
+		_, err := regexpEngine.FindStringSubmatch(manyGroups(DefaultMaxCaptureGroups+1), "urn:foo")
+		assert.ErrorIs(t, err, ErrTooManyCaptureGroups)
+	})
+
+	t.Run("case=default limit is not exceeded", func(t *testing.T) {
+		regexpEngine := new(regexpMatchingEngine)
+		got, err := regexpEngine.FindStringSubmatch(manyGroups(DefaultMaxCaptureGroups), "urn:foo"+strings.Repeat(":a", DefaultMaxCaptureGroups))
+		require.NoError(t, err)
+		assert.Len(t, got, DefaultMaxCaptureGroups)
+	})
+
+	t.Run("case=configured limit is exceeded", func(t *testing.T) {
+		regexpEngine := &regexpMatchingEngine{maxCaptureGroups: 2}
+		_, err := regexpEngine.IsMatching(manyGroups(3), "urn:foo:a:b:c")
+		assert.ErrorIs(t, err, ErrTooManyCaptureGroups)
+
+		_, _, err = regexpEngine.FindNamedStringSubmatchWithDefaults(manyGroups(3), "urn:foo:a:b:c", nil)
+		assert.ErrorIs(t, err, ErrTooManyCaptureGroups)
+	})
+
+	t.Run("case=configured limit is not exceeded", func(t *testing.T) {
+		regexpEngine := &regexpMatchingEngine{maxCaptureGroups: 2}
+		got, err := regexpEngine.FindStringSubmatch(manyGroups(2), "urn:foo:a:b")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, got)
+	})
+}
+
+func TestIsMatchingAny(t *testing.T) {
+	for k, tc := range []struct {
+		patterns []string
+		input    string
+		matches  bool
+		index    int
+	}{
+		{patterns: []string{"http://localhost/users/<[0-9]+>", "http://localhost/groups/<[0-9]+>"}, input: "http://localhost/groups/1", matches: true, index: 1},
+		{patterns: []string{"http://localhost/<.*>", "http://localhost/groups/<[0-9]+>"}, input: "http://localhost/groups/1", matches: true, index: 0},
+		{patterns: []string{"http://localhost/users/<[0-9]+>", "http://localhost/groups/<[0-9]+>"}, input: "http://localhost/roles/1", matches: false, index: -1},
+		{patterns: nil, input: "http://localhost/roles/1", matches: false, index: -1},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			regexpEngine := new(regexpMatchingEngine)
+			matches, index, err := regexpEngine.IsMatchingAny(tc.patterns, tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.matches, matches)
+			assert.Equal(t, tc.index, index)
+		})
+	}
+
+	t.Run("case=submatches follow the matching pattern", func(t *testing.T) {
+		patterns := []string{"http://localhost/users/<[0-9]+>", "http://localhost/groups/<[0-9]+>"}
+		regexpEngine := new(regexpMatchingEngine)
+		_, index, err := regexpEngine.IsMatchingAny(patterns, "http://localhost/groups/1")
+		require.NoError(t, err)
+
+		got, err := regexpEngine.FindStringSubmatch(patterns[index], "http://localhost/groups/1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1"}, got)
+	})
+
+	t.Run("case=invalid pattern", func(t *testing.T) {
+		regexpEngine := new(regexpMatchingEngine)
+		_, _, err := regexpEngine.IsMatchingAny([]string{"http://localhost/<(>"}, "http://localhost/")
+		require.Error(t, err)
+	})
+
+	t.Run("case=patterns are compiled once", func(t *testing.T) {
+		regexpEngine := new(regexpMatchingEngine)
+		patterns := []string{"http://localhost/users/<[0-9]+>", "http://localhost/groups/<[0-9]+>"}
+
+		_, _, err := regexpEngine.IsMatchingAny(patterns, "http://localhost/groups/1")
+		require.NoError(t, err)
+		compiled := regexpEngine.anyCompiled
+		require.Len(t, compiled, 2)
+
+		matches, index, err := regexpEngine.IsMatchingAny(patterns, "http://localhost/users/1")
+		require.NoError(t, err)
+		assert.True(t, matches)
+		assert.Equal(t, 0, index)
+		assert.Same(t, compiled[0], regexpEngine.anyCompiled[0])
+		assert.Same(t, compiled[1], regexpEngine.anyCompiled[1])
+
+		matches, _, err = regexpEngine.IsMatchingAny(patterns[1:], "http://localhost/users/1")
+		require.NoError(t, err)
+		assert.False(t, matches, "changed patterns are compiled again")
+	})
+}
+
+func TestAutoAnchor(t *testing.T) {
+	for k, tc := range []struct {
+		pattern string
+		input   string
+		matches bool
+	}{
+		{pattern: "http://localhost/admin", input: "http://localhost/admin", matches: true},
+		{pattern: "http://localhost/admin", input: "http://evil/?http://localhost/admin"},
+		{pattern: "http://localhost/admin", input: "http://localhost/admin/users"},
+		{pattern: "http://localhost/admin", input: "http://localhost/admin\n"},
+		{pattern: "http://localhost/<admin|public>", input: "http://localhost/public", matches: true},
+		{pattern: "http://localhost/<admin|public>", input: "http://localhost/not-admin"},
+		{pattern: "http://localhost/<admin|public>", input: "http://localhost/admin-not"},
+		{pattern: "http://localhost/<[a-z]*>", input: "http://localhost/admin\n"},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			regexpEngine := &regexpMatchingEngine{autoAnchor: true}
+			matches, err := regexpEngine.IsMatching(tc.pattern, tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.matches, matches)
+		})
+	}
+
+	t.Run("case=trailing newline only matches without the option", func(t *testing.T) {
+		for _, autoAnchor := range []bool{false, true} {
+			regexpEngine := &regexpMatchingEngine{autoAnchor: autoAnchor}
+			matches, err := regexpEngine.IsMatching("http://localhost/admin", "http://localhost/admin\n")
+			require.NoError(t, err)
+			assert.Equal(t, !autoAnchor, matches, "auto_anchor=%t", autoAnchor)
+		}
+	})
+
+	t.Run("case=capture groups are kept", func(t *testing.T) {
+		regexpEngine := &regexpMatchingEngine{autoAnchor: true}
+		pattern := "http://localhost/<(?P<resource>users|groups)>/<[0-9]+>"
+
+		got, err := regexpEngine.FindStringSubmatch(pattern, "http://localhost/users/1")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"users", "users", "1"}, got)
+
+		named, err := regexpEngine.FindNamedStringSubmatch(pattern, "http://localhost/users/1")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"resource": "users"}, named)
+	})
+}
@@ -162,6 +162,16 @@ func (f *FetcherDefault) Watch(ctx context.Context) error {
 		return err
 	}
 
+	maxCaptureGroups := f.config.AccessRuleMaxCaptureGroups()
+	if err := f.processMaxCaptureGroupsUpdate(ctx, maxCaptureGroups); err != nil {
+		return err
+	}
+
+	autoAnchor := f.config.AccessRuleAutoAnchor()
+	if err := f.registry.RuleRepository().SetAutoAnchor(ctx, autoAnchor); err != nil {
+		return err
+	}
+
 	remoteRepos := getRemoteRepos()
 	if err := f.processRemoteRepoUpdate(ctx, nil, remoteRepos); err != nil {
 		return err
@@ -184,6 +194,26 @@ func (f *FetcherDefault) Watch(ctx context.Context) error {
 			}
 		}
 
+		// update the capture group limit if it changed
+		if newMaxCaptureGroups := f.config.AccessRuleMaxCaptureGroups(); newMaxCaptureGroups != maxCaptureGroups {
+			f.registry.Logger().WithField("max_capture_groups", newMaxCaptureGroups).Info("Detected access rule capture group limit change, processing updates.")
+			if err := f.processMaxCaptureGroupsUpdate(ctx, newMaxCaptureGroups); err != nil {
+				f.registry.Logger().WithError(err).Error("Unable to update access rule capture group limit.")
+			} else {
+				maxCaptureGroups = newMaxCaptureGroups
+			}
+		}
+
+		// update the anchoring of regexp patterns if it changed
+		if newAutoAnchor := f.config.AccessRuleAutoAnchor(); newAutoAnchor != autoAnchor {
+			f.registry.Logger().WithField("auto_anchor", newAutoAnchor).Info("Detected access rule anchoring change, processing updates.")
+			if err := f.registry.RuleRepository().SetAutoAnchor(ctx, newAutoAnchor); err != nil {
+				f.registry.Logger().WithError(err).Error("Unable to update access rule anchoring.")
+			} else {
+				autoAnchor = newAutoAnchor
+			}
+		}
+
 		// update & fetch the remote repos if they changed
 		newRemoteRepos := getRemoteRepos()
 		if err := f.processRemoteRepoUpdate(ctx, remoteRepos, newRemoteRepos); err != nil {
@@ -203,6 +233,13 @@ func (f *FetcherDefault) processStrategyUpdate(ctx context.Context, newValue con
 	return nil
 }
 
+func (f *FetcherDefault) processMaxCaptureGroupsUpdate(ctx context.Context, newValue int) error {
+	if err := f.registry.RuleRepository().SetMaxCaptureGroups(ctx, newValue); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (f *FetcherDefault) processRemoteRepoUpdate(ctx context.Context, oldRepos, newRepos map[url.URL]struct{}) error {
 	repoChanged := false
 	for repo := range newRepos {
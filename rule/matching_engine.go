@@ -17,6 +17,7 @@ var (
 	ErrUnbalancedPattern       = errors.New("unbalanced pattern")
 	ErrMethodNotImplemented    = errors.New("the method is not implemented")
 	ErrUnknownMatchingStrategy = errors.New("unknown matching strategy")
+	ErrTooManyCaptureGroups    = errors.New("too many capture groups")
 )
 
 // MatchingEngine describes an interface of matching engine such as regexp or glob.
@@ -17,5 +17,8 @@ type Repository interface {
 	Count(context.Context) (int, error)
 	MatchingStrategy(context.Context) (configuration.MatchingStrategy, error)
 	SetMatchingStrategy(context.Context, configuration.MatchingStrategy) error
+	SetMaxCaptureGroups(context.Context, int) error
+	SetAutoAnchor(context.Context, bool) error
+	Validate(context.Context, []Rule) error
 	ReadyChecker(*http.Request) error
 }
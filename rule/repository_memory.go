@@ -30,6 +30,8 @@ type RepositoryMemory struct {
 	rules            []Rule
 	invalidRules     []Rule
 	matchingStrategy configuration.MatchingStrategy
+	maxCaptureGroups int
+	autoAnchor       bool
 	r                repositoryMemoryRegistry
 }
 
@@ -48,6 +50,36 @@ func (m *RepositoryMemory) SetMatchingStrategy(_ context.Context, ms configurati
 	return nil
 }
 
+// SetMaxCaptureGroups updates the maximum number of capture groups a regexp rule pattern may declare.
+func (m *RepositoryMemory) SetMaxCaptureGroups(_ context.Context, limit int) error {
+	m.Lock()
+	defer m.Unlock()
+	m.maxCaptureGroups = limit
+	for _, rules := range [][]Rule{m.rules, m.invalidRules} {
+		for k := range rules {
+			rules[k].maxCaptureGroups = limit
+			// Force the matching engine to be recreated with the new limit.
+			rules[k].matchingEngine = nil
+		}
+	}
+	return nil
+}
+
+// SetAutoAnchor updates whether regexp rule patterns are anchored to the start and end of the input.
+func (m *RepositoryMemory) SetAutoAnchor(_ context.Context, enabled bool) error {
+	m.Lock()
+	defer m.Unlock()
+	m.autoAnchor = enabled
+	for _, rules := range [][]Rule{m.rules, m.invalidRules} {
+		for k := range rules {
+			rules[k].autoAnchor = enabled
+			// Force the matching engine to be recreated with the new setting.
+			rules[k].matchingEngine = nil
+		}
+	}
+	return nil
+}
+
 func NewRepositoryMemory(r repositoryMemoryRegistry) *RepositoryMemory {
 	return &RepositoryMemory{
 		r:     r,
@@ -97,19 +129,65 @@ func (m *RepositoryMemory) Set(ctx context.Context, rules []Rule) error {
 	m.rules = make([]Rule, 0, len(rules))
 	m.invalidRules = make([]Rule, 0)
 
+	report := new(ValidationReport)
 	for _, check := range rules {
-		if err := m.r.RuleValidator().Validate(&check); err != nil {
-			m.r.Logger().WithError(err).WithField("rule_id", check.ID).
-				Errorf("A Rule uses a malformed configuration and all URLs matching this rule will not work. You should resolve this issue now.")
+		check.maxCaptureGroups = m.maxCaptureGroups
+		check.autoAnchor = m.autoAnchor
+		if errs := m.validate(check); len(errs) > 0 {
+			report.Errors = append(report.Errors, errs...)
 			m.invalidRules = append(m.invalidRules, check)
 		} else {
 			m.rules = append(m.rules, check)
 		}
 	}
 
+	if len(report.Errors) > 0 {
+		m.r.Logger().WithError(report).
+			Errorf("Some rules use a malformed configuration and all URLs matching these rules will not work. You should resolve this issue now.")
+	}
+
 	return nil
 }
 
+// Validate validates rules like Set. Instead of stopping at the first invalid rule, it returns a
+// *ValidationReport with the errors of all invalid rules. The rules of the repository are left unchanged.
+func (m *RepositoryMemory) Validate(_ context.Context, rules []Rule) error {
+	m.RLock()
+	defer m.RUnlock()
+
+	report := new(ValidationReport)
+	for _, check := range rules {
+		report.Errors = append(report.Errors, m.validate(check)...)
+	}
+
+	if len(report.Errors) > 0 {
+		return report
+	}
+	return nil
+}
+
+// validate validates rule with the rule validator, checks the scope strategies of its authenticators and
+// compiles its pattern with the current matching strategy. The caller must hold the lock.
+func (m *RepositoryMemory) validate(rule Rule) []RuleError {
+	rule.maxCaptureGroups = m.maxCaptureGroups
+	rule.autoAnchor = m.autoAnchor
+	rule.matchingEngine = nil
+
+	var errs []RuleError
+	if err := m.r.RuleValidator().Validate(&rule); err != nil {
+		errs = append(errs, RuleError{RuleID: rule.ID, Err: err})
+	}
+	if err := validateScopeStrategies(&rule); err != nil {
+		errs = append(errs, RuleError{RuleID: rule.ID, Err: err})
+	}
+	if rule.Match != nil && rule.Match.GetURL() != "" {
+		if err := compilePattern(&rule, m.matchingStrategy); err != nil {
+			errs = append(errs, RuleError{RuleID: rule.ID, Err: err})
+		}
+	}
+	return errs
+}
+
 func (m *RepositoryMemory) Match(ctx context.Context, method string, u *url.URL, protocol Protocol) (*Rule, error) {
 	if u == nil {
 		return nil, errors.WithStack(errors.New("nil URL provided"))
@@ -5,6 +5,7 @@ package rule
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"net/http"
@@ -153,3 +154,66 @@ func TestRepository(t *testing.T) {
 		})
 	}
 }
+
+func TestRepositoryMemoryValidate(t *testing.T) {
+	repo := NewRepositoryMemory(new(mockRepositoryRegistry))
+	require.NoError(t, repo.SetMatchingStrategy(context.Background(), configuration.Regexp))
+	require.NoError(t, repo.SetMaxCaptureGroups(context.Background(), 1))
+
+	rules := []Rule{
+		{ID: "valid", Match: &Match{URL: "https://example.com/<.*>"}},
+		{ID: "unbalanced", Match: &Match{URL: "https://example.com/<.*"}},
+		{ID: "also-valid", Match: &Match{URL: "https://example.com/users/<[0-9]+>"}},
+		{ID: "malformed", Match: &Match{URL: "https://example.com/<[0-9>"}},
+		{ID: "too-many-groups", Match: &Match{URL: "https://example.com/<(a)>/<(b)>"}},
+		{ID: "unknown-scope-strategy", Match: &Match{URL: "https://example.com/"}, Authenticators: []Handler{
+			{Handler: "jwt", Config: json.RawMessage(`{"scope_strategy":"regexp"}`)},
+		}},
+		{ID: "unchecked-scopes", Match: &Match{URL: "https://example.com/"}, Authenticators: []Handler{
+			{Handler: "noop"},
+			{Handler: "jwt", Config: json.RawMessage(`{"scope_strategy":"none","required_scope":["users.read"]}`)},
+		}},
+		{ID: "checked-scopes", Match: &Match{URL: "https://example.com/"}, Authenticators: []Handler{
+			{Handler: "jwt", Config: json.RawMessage(`{"scope_strategy":"Wildcard","required_scope":["users.read"]}`)},
+		}},
+	}
+
+	err := repo.Validate(context.Background(), rules)
+	require.Error(t, err)
+
+	var report *ValidationReport
+	require.ErrorAs(t, err, &report)
+	ids := make([]string, len(report.Errors))
+	for k, ruleErr := range report.Errors {
+		ids[k] = ruleErr.RuleID
+	}
+	assert.Equal(t, []string{"unbalanced", "malformed", "too-many-groups", "unknown-scope-strategy", "unchecked-scopes"}, ids)
+	assert.ErrorIs(t, report.Errors[2], ErrTooManyCaptureGroups)
+	assert.Contains(t, err.Error(), `rule "malformed"`)
+	assert.Contains(t, err.Error(), `authenticators[0].config.scope_strategy`)
+	assert.Contains(t, err.Error(), `authenticators[1].config.required_scope`)
+
+	count, err := repo.Count(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, count, "validating must not change the repository")
+
+	require.NoError(t, repo.Validate(context.Background(), []Rule{rules[0], rules[2], rules[7]}))
+}
+
+func TestRepositoryMemorySetReportsInvalidRules(t *testing.T) {
+	registry := new(mockRepositoryRegistry)
+	repo := NewRepositoryMemory(registry)
+	require.NoError(t, repo.SetMatchingStrategy(context.Background(), configuration.Regexp))
+
+	require.NoError(t, repo.Set(context.Background(), []Rule{
+		{ID: "valid", Match: &Match{URL: "https://example.com/<.*>"}},
+		{ID: "unbalanced", Match: &Match{URL: "https://example.com/<.*"}},
+		{ID: "malformed", Match: &Match{URL: "https://example.com/<[0-9>"}},
+	}))
+	assert.Equal(t, 1, registry.loggerCalled, "all invalid rules are reported at once")
+
+	rules, err := repo.List(context.Background(), 10, 0)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "valid", rules[0].ID)
+}
@@ -126,7 +126,9 @@ type Rule struct {
 	// Upstream is the location of the server where requests matching this rule should be forwarded to.
 	Upstream Upstream `json:"upstream"`
 
-	matchingEngine MatchingEngine
+	matchingEngine   MatchingEngine
+	maxCaptureGroups int
+	autoAnchor       bool
 }
 
 type Upstream struct {
@@ -250,13 +252,25 @@ func ensureMatchingEngine(rule *Rule, strategy configuration.MatchingStrategy) e
 		rule.matchingEngine = new(globMatchingEngine)
 		return nil
 	case "", configuration.Regexp:
-		rule.matchingEngine = new(regexpMatchingEngine)
+		rule.matchingEngine = &regexpMatchingEngine{maxCaptureGroups: rule.maxCaptureGroups, autoAnchor: rule.autoAnchor}
 		return nil
 	}
 
 	return errors.Wrap(ErrUnknownMatchingStrategy, string(strategy))
 }
 
+// compilePattern compiles the pattern of the rule with the matching engine of strategy so that malformed
+// patterns are detected before the rule is matched against a request.
+func compilePattern(rule *Rule, strategy configuration.MatchingStrategy) error {
+	if err := ensureMatchingEngine(rule, strategy); err != nil {
+		return err
+	}
+	if engine, ok := rule.matchingEngine.(interface{ compile(pattern string) error }); ok {
+		return engine.compile(rule.Match.GetURL())
+	}
+	return nil
+}
+
 // ExtractRegexGroups returns the values matching the rule pattern
 func (r *Rule) ExtractRegexGroups(strategy configuration.MatchingStrategy, u *url.URL) ([]string, error) {
 	if err := ensureMatchingEngine(r, strategy); err != nil {
@@ -4,12 +4,17 @@
 package rule
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/url"
+	"slices"
+	"strings"
 
 	"github.com/pkg/errors"
 
 	"github.com/ory/herodot"
 
+	"github.com/ory/oathkeeper/driver/configuration"
 	"github.com/ory/oathkeeper/pipeline/authn"
 	"github.com/ory/oathkeeper/pipeline/authz"
 	pe "github.com/ory/oathkeeper/pipeline/errors"
@@ -29,6 +34,36 @@ type Validator interface {
 
 var _ Validator = new(ValidatorDefault)
 
+// RuleError is the validation error of a single rule.
+type RuleError struct {
+	RuleID string
+	Err    error
+}
+
+func (e RuleError) Error() string {
+	if reason, ok := errors.Cause(e.Err).(interface{ Reason() string }); ok && reason.Reason() != "" {
+		return fmt.Sprintf(`rule "%s": %s: %s`, e.RuleID, e.Err, reason.Reason())
+	}
+	return fmt.Sprintf(`rule "%s": %s`, e.RuleID, e.Err)
+}
+
+func (e RuleError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationReport collects the validation errors of all invalid rules of a rule set.
+type ValidationReport struct {
+	Errors []RuleError
+}
+
+func (r *ValidationReport) Error() string {
+	lines := make([]string, len(r.Errors))
+	for k, err := range r.Errors {
+		lines[k] = err.Error()
+	}
+	return fmt.Sprintf("%d validation error(s) in access rules:\n%s", len(r.Errors), strings.Join(lines, "\n"))
+}
+
 type ValidatorDefault struct {
 	r validatorRegistry
 }
@@ -56,6 +91,31 @@ func (v *ValidatorDefault) validateAuthenticators(r *Rule) error {
 	return nil
 }
 
+// validateScopeStrategies checks the scope strategies the authenticators of r declare. Scope strategies of
+// the global configuration are validated when the configuration is loaded.
+func validateScopeStrategies(r *Rule) error {
+	for k, a := range r.Authenticators {
+		var c struct {
+			ScopeStrategy *string  `json:"scope_strategy"`
+			Scope         []string `json:"required_scope"`
+		}
+		if err := json.Unmarshal(a.Config, &c); err != nil || c.ScopeStrategy == nil {
+			// Malformed configurations are reported by the authenticator.
+			continue
+		}
+
+		strategy := strings.ToLower(*c.ScopeStrategy)
+		if !slices.Contains(configuration.ScopeStrategies, strategy) {
+			return errors.WithStack(herodot.ErrInternalServerError.WithReasonf(`Value "%s" of "authenticators[%d].config.scope_strategy" is not in list of supported scope strategies: %v`, *c.ScopeStrategy, k, configuration.ScopeStrategies))
+		}
+		if strategy == "none" && len(c.Scope) > 0 && a.Handler == "jwt" {
+			return errors.WithStack(herodot.ErrInternalServerError.WithReasonf(`Value "%s" of "authenticators[%d].config.scope_strategy" can not check the scopes of "authenticators[%d].config.required_scope".`, *c.ScopeStrategy, k, k))
+		}
+	}
+
+	return nil
+}
+
 func (v *ValidatorDefault) validateAuthorizer(r *Rule) error {
 	if r.Authorizer.Handler == "" {
 		return errors.WithStack(herodot.ErrInternalServerError.WithReason(`Value of "authorizer.handler" can not be empty.`))
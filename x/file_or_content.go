@@ -0,0 +1,26 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FileOrContent returns the contents of the file in refers to if in is a file:// URL, and in itself
+// otherwise.
+func FileOrContent(in string) (string, error) {
+	path, ok := strings.CutPrefix(in, "file://")
+	if !ok {
+		return in, nil
+	}
+
+	content, err := os.ReadFile(path) //nolint:gosec // the path is taken from the configuration
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return string(content), nil
+}
@@ -0,0 +1,219 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+// Package scopex provides scope strategies and helpers to compare OAuth 2.0 scopes.
+package scopex
+
+import (
+	"strings"
+
+	"github.com/ory/fosite"
+)
+
+// NewHierarchicScopeStrategy returns a hierarchic scope strategy where a granted scope only grants scopes
+// at most maxDepth levels below it, so that with a maxDepth of 1 "a" grants "a.b" but not "a.b.c". A
+// maxDepth of 0 does not limit the depth and behaves like fosite.HierarchicScopeStrategy.
+func NewHierarchicScopeStrategy(maxDepth int) fosite.ScopeStrategy {
+	if maxDepth <= 0 {
+		return fosite.HierarchicScopeStrategy
+	}
+	return NewSegmentScopeStrategy(maxDepth, nil)
+}
+
+// NewSegmentScopeStrategy returns a hierarchic scope strategy which compares the dot-separated segments of
+// scopes with segmentEqual, for example to ignore a version suffix. A nil segmentEqual compares segments
+// exactly. maxDepth limits the levels below a granted scope like in NewHierarchicScopeStrategy.
+func NewSegmentScopeStrategy(maxDepth int, segmentEqual func(a, b string) bool) fosite.ScopeStrategy {
+	if segmentEqual == nil {
+		segmentEqual = func(a, b string) bool { return a == b }
+	}
+
+	return func(haystack []string, needle string) bool {
+		required := strings.Split(needle, ".")
+		for _, scope := range haystack {
+			if matchFrom(strings.Split(scope, "."), required, maxDepth, segmentEqual) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// matchFrom reports whether the granted scope segments are a prefix of the required ones, at most
+// maxDepth segments shorter unless maxDepth is 0.
+func matchFrom(granted, required []string, maxDepth int, segmentEqual func(a, b string) bool) bool {
+	if len(granted) > len(required) || (maxDepth > 0 && len(required)-len(granted) > maxDepth) {
+		return false
+	}
+	return divergeAt(granted, required, segmentEqual) < 0
+}
+
+// divergeAt returns the index of the first granted segment which does not equal the required segment at
+// the same position, or -1 if all of them do. required must have at least as many segments as granted.
+func divergeAt(granted, required []string, segmentEqual func(a, b string) bool) int {
+	for k := range granted {
+		if !segmentEqual(granted[k], required[k]) {
+			return k
+		}
+	}
+	return -1
+}
+
+// ScopeMismatch is the reason why a scope pattern does not match a candidate scope.
+type ScopeMismatch string
+
+const (
+	// ScopeMismatchLiteral means that a literal segment of the pattern differs from the candidate's.
+	ScopeMismatchLiteral ScopeMismatch = "literal_mismatch"
+	// ScopeMismatchEmptySegment means that a wildcard of the pattern met an empty segment of the candidate.
+	ScopeMismatchEmptySegment ScopeMismatch = "empty_segment"
+	// ScopeMismatchPatternExhausted means that the candidate has more segments than the pattern, which
+	// does not end with a wildcard.
+	ScopeMismatchPatternExhausted ScopeMismatch = "pattern_exhausted"
+	// ScopeMismatchCandidateExhausted means that the pattern has more segments than the candidate.
+	ScopeMismatchCandidateExhausted ScopeMismatch = "candidate_exhausted"
+)
+
+// Explanation describes where a scope pattern stopped matching a candidate scope. Segment is the index of
+// the first diverging dot-separated segment. Both are zero values if the pattern matches.
+type Explanation struct {
+	Segment int           `json:"segment"`
+	Reason  ScopeMismatch `json:"reason,omitempty"`
+}
+
+// MatchExplain matches candidate against pattern like fosite.WildcardScopeStrategy and, if they do not
+// match, explains which segment diverged and why.
+func MatchExplain(pattern, candidate string) (bool, Explanation) {
+	granted, required := strings.Split(pattern, "."), strings.Split(candidate, ".")
+	n := min(len(granted), len(required))
+
+	if k := divergeAt(granted[:n], required, wildcardSegmentEqual); k >= 0 {
+		if granted[k] == "*" {
+			return false, Explanation{Segment: k, Reason: ScopeMismatchEmptySegment}
+		}
+		return false, Explanation{Segment: k, Reason: ScopeMismatchLiteral}
+	}
+
+	switch {
+	case len(granted) > len(required):
+		return false, Explanation{Segment: n, Reason: ScopeMismatchCandidateExhausted}
+	case len(granted) < len(required) && granted[n-1] != "*":
+		return false, Explanation{Segment: n, Reason: ScopeMismatchPatternExhausted}
+	}
+	return true, Explanation{}
+}
+
+// wildcardSegmentEqual compares segments like fosite.WildcardScopeStrategy, where "*" equals any non-empty
+// segment.
+func wildcardSegmentEqual(pattern, candidate string) bool {
+	return pattern == candidate || (pattern == "*" && candidate != "")
+}
+
+// StripScopePrefix returns a copy of in where prefix has been removed from every scope that carries it.
+// Scopes without the prefix are returned unchanged.
+func StripScopePrefix(prefix string, in []string) []string {
+	if prefix == "" {
+		return in
+	}
+
+	out := make([]string, len(in))
+	for k, scope := range in {
+		out[k] = strings.TrimPrefix(scope, prefix)
+	}
+	return out
+}
+
+// WithScopePrefix wraps strategy so that prefix is stripped from the granted scopes before they are
+// compared to the required scope. A nil strategy stays nil.
+func WithScopePrefix(prefix string, strategy fosite.ScopeStrategy) fosite.ScopeStrategy {
+	if prefix == "" || strategy == nil {
+		return strategy
+	}
+
+	return func(haystack []string, needle string) bool {
+		return strategy(StripScopePrefix(prefix, haystack), needle)
+	}
+}
+
+// AnyOf returns a scope strategy which grants needle if any of strategies grants it, for example to
+// accept both wildcard and hierarchic scopes while migrating from one to the other. Nil strategies are
+// ignored, and nil is returned if no strategy remains.
+func AnyOf(strategies ...fosite.ScopeStrategy) fosite.ScopeStrategy {
+	strategies = nonNilStrategies(strategies)
+	if len(strategies) == 0 {
+		return nil
+	}
+
+	return func(haystack []string, needle string) bool {
+		for _, strategy := range strategies {
+			if strategy(haystack, needle) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AllOf returns a scope strategy which only grants needle if all of strategies grant it. Nil strategies
+// are ignored, and nil is returned if no strategy remains.
+func AllOf(strategies ...fosite.ScopeStrategy) fosite.ScopeStrategy {
+	strategies = nonNilStrategies(strategies)
+	if len(strategies) == 0 {
+		return nil
+	}
+
+	return func(haystack []string, needle string) bool {
+		for _, strategy := range strategies {
+			if !strategy(haystack, needle) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// nonNilStrategies returns a copy of strategies without nil strategies.
+func nonNilStrategies(strategies []fosite.ScopeStrategy) []fosite.ScopeStrategy {
+	out := make([]fosite.ScopeStrategy, 0, len(strategies))
+	for _, strategy := range strategies {
+		if strategy != nil {
+			out = append(out, strategy)
+		}
+	}
+	return out
+}
+
+// RequiredScope returns the least privileged scope a token must be granted to satisfy needle, which for
+// the exact, hierarchic and wildcard strategies is the literal needle carrying prefix. strategy compares the
+// granted scopes and must already strip prefix, see WithScopePrefix. An empty string is returned if strategy
+// is nil or is not satisfied by that scope.
+func RequiredScope(prefix, needle string, strategy fosite.ScopeStrategy) string {
+	if strategy == nil {
+		return ""
+	}
+	if required := prefix + needle; strategy([]string{required}, needle) {
+		return required
+	}
+	return ""
+}
+
+// ScopeDiff returns the scopes of new which are not covered by old (added) and the scopes of old which are
+// no longer covered by new (removed), as judged by strategy. A nil strategy compares scopes exactly.
+func ScopeDiff(old, new []string, strategy fosite.ScopeStrategy) (added, removed []string) {
+	if strategy == nil {
+		strategy = fosite.ExactScopeStrategy
+	}
+
+	added, removed = []string{}, []string{}
+	for _, scope := range new {
+		if !strategy(old, scope) {
+			added = append(added, scope)
+		}
+	}
+	for _, scope := range old {
+		if !strategy(new, scope) {
+			removed = append(removed, scope)
+		}
+	}
+	return added, removed
+}
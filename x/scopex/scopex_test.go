@@ -0,0 +1,180 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package scopex_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ory/fosite"
+
+	"github.com/ory/oathkeeper/x/scopex"
+)
+
+func TestStripScopePrefix(t *testing.T) {
+	assert.Equal(t, []string{"read.users", "write.users"}, scopex.StripScopePrefix("myapp:", []string{"myapp:read.users", "write.users"}))
+	assert.Equal(t, []string{"myapp:read.users"}, scopex.StripScopePrefix("", []string{"myapp:read.users"}))
+	assert.Empty(t, scopex.StripScopePrefix("myapp:", nil))
+
+	in := []string{"myapp:read.users"}
+	scopex.StripScopePrefix("myapp:", in)
+	assert.Equal(t, []string{"myapp:read.users"}, in, "the input must not be modified")
+}
+
+func TestWithScopePrefix(t *testing.T) {
+	ss := scopex.WithScopePrefix("myapp:", fosite.HierarchicScopeStrategy)
+	assert.True(t, ss([]string{"myapp:read"}, "read.users"))
+	assert.True(t, ss([]string{"read"}, "read.users"))
+	assert.False(t, ss([]string{"otherapp:read"}, "read.users"))
+	assert.False(t, ss([]string{"myapp:read"}, "myapp:read"))
+
+	assert.False(t, fosite.HierarchicScopeStrategy([]string{"myapp:read"}, "read.users"))
+	assert.True(t, scopex.WithScopePrefix("", fosite.ExactScopeStrategy)([]string{"read"}, "read"))
+	assert.Nil(t, scopex.WithScopePrefix("myapp:", nil))
+}
+
+func TestAnyOfAllOf(t *testing.T) {
+	// "a.b" is granted by "a" only under the hierarchic strategy and by "a.*" only under the wildcard one.
+	for _, tc := range []struct {
+		haystack []string
+		any, all bool
+	}{
+		{haystack: []string{"a"}, any: true, all: false},
+		{haystack: []string{"a.*"}, any: true, all: false},
+		{haystack: []string{"a.b"}, any: true, all: true},
+		{haystack: []string{"b"}, any: false, all: false},
+	} {
+		t.Run("haystack="+strings.Join(tc.haystack, ","), func(t *testing.T) {
+			assert.Equal(t, tc.any, scopex.AnyOf(fosite.WildcardScopeStrategy, fosite.HierarchicScopeStrategy)(tc.haystack, "a.b"))
+			assert.Equal(t, tc.all, scopex.AllOf(fosite.WildcardScopeStrategy, fosite.HierarchicScopeStrategy)(tc.haystack, "a.b"))
+		})
+	}
+
+	assert.True(t, scopex.AnyOf(nil, fosite.ExactScopeStrategy)([]string{"a"}, "a"))
+	assert.True(t, scopex.AllOf(fosite.ExactScopeStrategy, nil)([]string{"a"}, "a"))
+	assert.Nil(t, scopex.AnyOf())
+	assert.Nil(t, scopex.AllOf(nil))
+}
+
+func TestNewHierarchicScopeStrategy(t *testing.T) {
+	for k, tc := range []struct {
+		maxDepth int
+		haystack []string
+		needle   string
+		expect   bool
+	}{
+		{maxDepth: 0, haystack: []string{"a"}, needle: "a.b.c", expect: true},
+		{maxDepth: 1, haystack: []string{"a"}, needle: "a", expect: true},
+		{maxDepth: 1, haystack: []string{"a"}, needle: "a.b", expect: true},
+		{maxDepth: 1, haystack: []string{"a"}, needle: "a.b.c", expect: false},
+		{maxDepth: 1, haystack: []string{"a", "a.b"}, needle: "a.b.c", expect: true},
+		{maxDepth: 1, haystack: []string{"a.b"}, needle: "a", expect: false},
+		{maxDepth: 1, haystack: []string{"b"}, needle: "a.b", expect: false},
+		{maxDepth: 2, haystack: []string{"a"}, needle: "a.b.c", expect: true},
+		{maxDepth: 2, haystack: []string{"a"}, needle: "a.b.c.d", expect: false},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			assert.Equal(t, tc.expect, scopex.NewHierarchicScopeStrategy(tc.maxDepth)(tc.haystack, tc.needle))
+		})
+	}
+}
+
+func TestNewSegmentScopeStrategy(t *testing.T) {
+	ignoreVersion := func(a, b string) bool {
+		a, _, _ = strings.Cut(a, "@")
+		b, _, _ = strings.Cut(b, "@")
+		return a == b
+	}
+
+	for k, tc := range []struct {
+		maxDepth     int
+		segmentEqual func(a, b string) bool
+		haystack     []string
+		needle       string
+		expect       bool
+	}{
+		{haystack: []string{"users@v1"}, needle: "users@v2", expect: false},
+		{haystack: []string{"users"}, needle: "users.read", expect: true},
+		{segmentEqual: ignoreVersion, haystack: []string{"users@v1"}, needle: "users@v2", expect: true},
+		{segmentEqual: ignoreVersion, haystack: []string{"users@v1"}, needle: "users@v2.read@v3", expect: true},
+		{segmentEqual: ignoreVersion, haystack: []string{"users@v1.read"}, needle: "users@v2.write", expect: false},
+		{segmentEqual: ignoreVersion, haystack: []string{"users@v1.read"}, needle: "users", expect: false},
+		{maxDepth: 1, segmentEqual: ignoreVersion, haystack: []string{"users@v1"}, needle: "users.read@v2.own", expect: false},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			assert.Equal(t, tc.expect, scopex.NewSegmentScopeStrategy(tc.maxDepth, tc.segmentEqual)(tc.haystack, tc.needle))
+		})
+	}
+}
+
+func TestMatchExplain(t *testing.T) {
+	for k, tc := range []struct {
+		pattern, candidate string
+		expect             bool
+		explanation        scopex.Explanation
+	}{
+		{pattern: "users.read", candidate: "users.read", expect: true},
+		{pattern: "users.*", candidate: "users.read.own", expect: true},
+		{pattern: "*", candidate: "users", expect: true},
+		{pattern: "users.read", candidate: "users.write", explanation: scopex.Explanation{Segment: 1, Reason: scopex.ScopeMismatchLiteral}},
+		{pattern: "users.*.own", candidate: "groups.read.own", explanation: scopex.Explanation{Segment: 0, Reason: scopex.ScopeMismatchLiteral}},
+		{pattern: "users.*", candidate: "users.", explanation: scopex.Explanation{Segment: 1, Reason: scopex.ScopeMismatchEmptySegment}},
+		{pattern: "users.read", candidate: "users.read.own", explanation: scopex.Explanation{Segment: 2, Reason: scopex.ScopeMismatchPatternExhausted}},
+		{pattern: "users.read.own", candidate: "users.read", explanation: scopex.Explanation{Segment: 2, Reason: scopex.ScopeMismatchCandidateExhausted}},
+		{pattern: "users.*.own", candidate: "users.read", explanation: scopex.Explanation{Segment: 2, Reason: scopex.ScopeMismatchCandidateExhausted}},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			matches, explanation := scopex.MatchExplain(tc.pattern, tc.candidate)
+			assert.Equal(t, tc.expect, matches)
+			assert.Equal(t, tc.explanation, explanation)
+			assert.Equal(t, fosite.WildcardScopeStrategy([]string{tc.pattern}, tc.candidate), matches, "must agree with the wildcard strategy")
+		})
+	}
+}
+
+func TestRequiredScope(t *testing.T) {
+	for k, tc := range []struct {
+		prefix, needle string
+		strategy       fosite.ScopeStrategy
+		expect         string
+	}{
+		{needle: "users.read", strategy: fosite.ExactScopeStrategy, expect: "users.read"},
+		{needle: "users.read.own", strategy: fosite.HierarchicScopeStrategy, expect: "users.read.own"},
+		{needle: "users.read", strategy: fosite.WildcardScopeStrategy, expect: "users.read"},
+		{prefix: "api:", needle: "users.read", strategy: scopex.WithScopePrefix("api:", fosite.WildcardScopeStrategy), expect: "api:users.read"},
+		{prefix: "api:", needle: "users.read", strategy: scopex.WithScopePrefix("api:", fosite.HierarchicScopeStrategy), expect: "api:users.read"},
+		{needle: "users.read", strategy: nil, expect: ""},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			assert.Equal(t, tc.expect, scopex.RequiredScope(tc.prefix, tc.needle, tc.strategy))
+		})
+	}
+}
+
+func TestScopeDiff(t *testing.T) {
+	for k, tc := range []struct {
+		old, new       []string
+		strategy       fosite.ScopeStrategy
+		added, removed []string
+	}{
+		{old: []string{"read", "write"}, new: []string{"read", "write"}, strategy: fosite.ExactScopeStrategy, added: []string{}, removed: []string{}},
+		{old: []string{"read"}, new: []string{"read", "write"}, strategy: fosite.ExactScopeStrategy, added: []string{"write"}, removed: []string{}},
+		{old: []string{"read", "write"}, new: []string{"read"}, strategy: nil, added: []string{}, removed: []string{"write"}},
+		// The parent scope still covers the child, so nothing was effectively removed.
+		{old: []string{"users", "users.read"}, new: []string{"users"}, strategy: fosite.HierarchicScopeStrategy, added: []string{}, removed: []string{}},
+		// Narrowing the parent down to one child removes the parent.
+		{old: []string{"users"}, new: []string{"users.read"}, strategy: fosite.HierarchicScopeStrategy, added: []string{}, removed: []string{"users"}},
+		{old: []string{"users.read", "users.write"}, new: []string{"users.read"}, strategy: fosite.HierarchicScopeStrategy, added: []string{}, removed: []string{"users.write"}},
+		{old: []string{"users.read"}, new: []string{"users"}, strategy: fosite.HierarchicScopeStrategy, added: []string{"users"}, removed: []string{}},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			added, removed := scopex.ScopeDiff(tc.old, tc.new, tc.strategy)
+			assert.Equal(t, tc.added, added)
+			assert.Equal(t, tc.removed, removed)
+		})
+	}
+}